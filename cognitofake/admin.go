@@ -0,0 +1,436 @@
+package cognitofake
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Purpose passed to CodeSender for the invitation of an administrator-created user, the code is the temporary password
+const CodeInvitation = "invitation"
+
+func (c *Client) AdminCreateUser(ctx context.Context, params *cognitoidentityprovider.AdminCreateUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminCreateUserOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminCreateUser", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	username := aws.ToString(params.Username)
+	if _, exists := c.users[username]; exists {
+		return nil, &types.UsernameExistsException{Message: aws.String("User account already exists")}
+	}
+
+	password := aws.ToString(params.TemporaryPassword)
+	if password == "" {
+		password = "Temp-" + randomHex(8) + "a1"
+	} else if err := checkPasswordPolicy(password); err != nil {
+		return nil, err
+	}
+
+	for _, attribute := range params.UserAttributes {
+		if err := checkWritableAttribute(aws.ToString(attribute.Name)); err != nil && !isVerifiedFlag(aws.ToString(attribute.Name)) {
+			return nil, err
+		}
+	}
+
+	// Administrators create confirmed users who must replace the temporary password
+	user := &User{
+		Username:            username,
+		Password:            password,
+		Attributes:          map[string]string{"sub": randomHex(16)},
+		Confirmed:           true,
+		ForceChangePassword: true,
+		CreatedAt:           time.Now(),
+	}
+	for _, attribute := range params.UserAttributes {
+		user.Attributes[aws.ToString(attribute.Name)] = aws.ToString(attribute.Value)
+	}
+	c.users[username] = user
+
+	if params.MessageAction != types.MessageActionTypeSuppress && c.CodeSender != nil {
+		c.CodeSender(*user, CodeInvitation, password)
+	}
+
+	return &cognitoidentityprovider.AdminCreateUserOutput{User: userType(user)}, nil
+}
+
+func (c *Client) AdminGetUser(ctx context.Context, params *cognitoidentityprovider.AdminGetUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminGetUserOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminGetUser", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(aws.ToString(params.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := userType(user)
+	output := &cognitoidentityprovider.AdminGetUserOutput{
+		Username:             summary.Username,
+		UserAttributes:       summary.Attributes,
+		Enabled:              summary.Enabled,
+		UserStatus:           summary.UserStatus,
+		UserCreateDate:       summary.UserCreateDate,
+		UserLastModifiedDate: summary.UserLastModifiedDate,
+		UserMFASettingList:   slices.Clone(user.EnabledMFA),
+	}
+	if user.PreferredMFA != "" {
+		output.PreferredMfaSetting = aws.String(user.PreferredMFA)
+	}
+
+	return output, nil
+}
+
+func (c *Client) AdminDisableUser(ctx context.Context, params *cognitoidentityprovider.AdminDisableUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDisableUserOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminDisableUser", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(aws.ToString(params.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	user.Disabled = true
+
+	return &cognitoidentityprovider.AdminDisableUserOutput{}, nil
+}
+
+func (c *Client) AdminEnableUser(ctx context.Context, params *cognitoidentityprovider.AdminEnableUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminEnableUserOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminEnableUser", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(aws.ToString(params.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	user.Disabled = false
+
+	return &cognitoidentityprovider.AdminEnableUserOutput{}, nil
+}
+
+func (c *Client) AdminResetUserPassword(ctx context.Context, params *cognitoidentityprovider.AdminResetUserPasswordInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminResetUserPasswordOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminResetUserPassword", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(aws.ToString(params.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Attributes["email_verified"] != "true" {
+		return nil, &types.InvalidParameterException{Message: aws.String("Cannot reset password for the user as there is no registered/verified email or phone_number")}
+	}
+
+	user.PasswordResetRequired = true
+	c.sendCode(user, CodeForgotPassword)
+
+	return &cognitoidentityprovider.AdminResetUserPasswordOutput{}, nil
+}
+
+func (c *Client) AdminSetUserPassword(ctx context.Context, params *cognitoidentityprovider.AdminSetUserPasswordInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminSetUserPasswordOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminSetUserPassword", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(aws.ToString(params.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPasswordPolicy(aws.ToString(params.Password)); err != nil {
+		return nil, err
+	}
+
+	user.Password = aws.ToString(params.Password)
+	user.PasswordResetRequired = false
+	// A permanent password confirms the user, a temporary one must be replaced at the next sign in
+	user.ForceChangePassword = !params.Permanent
+	if params.Permanent {
+		user.Confirmed = true
+	}
+
+	return &cognitoidentityprovider.AdminSetUserPasswordOutput{}, nil
+}
+
+func (c *Client) AdminDeleteUser(ctx context.Context, params *cognitoidentityprovider.AdminDeleteUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDeleteUserOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminDeleteUser", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(aws.ToString(params.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	c.removeUser(user)
+
+	return &cognitoidentityprovider.AdminDeleteUserOutput{}, nil
+}
+
+func (c *Client) AdminAddUserToGroup(ctx context.Context, params *cognitoidentityprovider.AdminAddUserToGroupInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminAddUserToGroupOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminAddUserToGroup", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, group, err := c.findMembership(aws.ToString(params.Username), aws.ToString(params.GroupName))
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(user.Groups, group.Name) {
+		user.Groups = append(user.Groups, group.Name)
+	}
+
+	return &cognitoidentityprovider.AdminAddUserToGroupOutput{}, nil
+}
+
+func (c *Client) AdminRemoveUserFromGroup(ctx context.Context, params *cognitoidentityprovider.AdminRemoveUserFromGroupInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminRemoveUserFromGroupOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminRemoveUserFromGroup", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, group, err := c.findMembership(aws.ToString(params.Username), aws.ToString(params.GroupName))
+	if err != nil {
+		return nil, err
+	}
+
+	user.Groups = without(user.Groups, group.Name)
+
+	return &cognitoidentityprovider.AdminRemoveUserFromGroupOutput{}, nil
+}
+
+func (c *Client) ListUsers(ctx context.Context, params *cognitoidentityprovider.ListUsersInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListUsersOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("ListUsers", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	match, err := parseFilter(aws.ToString(params.Filter))
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*User
+	for _, user := range c.users {
+		if match(user) {
+			users = append(users, user)
+		}
+	}
+
+	page, next, err := paginate(users, func(user *User) string { return user.Username }, params.Limit, params.PaginationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &cognitoidentityprovider.ListUsersOutput{Users: []types.UserType{}, PaginationToken: next}
+	for _, user := range page {
+		output.Users = append(output.Users, *userType(user))
+	}
+
+	return output, nil
+}
+
+func (c *Client) AdminUserGlobalSignOut(ctx context.Context, params *cognitoidentityprovider.AdminUserGlobalSignOutInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminUserGlobalSignOutOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminUserGlobalSignOut", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(aws.ToString(params.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	c.revokeTokens(user)
+
+	return &cognitoidentityprovider.AdminUserGlobalSignOutOutput{}, nil
+}
+
+// Returns an injected failure, then checks the pool when the fake's UserPoolID is set
+func (c *Client) checkAdminCall(operation string, userPoolId *string) error {
+	if err := c.takeFailure(operation); err != nil {
+		return err
+	}
+
+	if c.UserPoolID != "" && aws.ToString(userPoolId) != c.UserPoolID {
+		return &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("User pool %s does not exist.", aws.ToString(userPoolId)))}
+	}
+
+	return nil
+}
+
+func (c *Client) findGroup(name string) (*Group, error) {
+	group, ok := c.groups[name]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Group not found.")}
+	}
+	return group, nil
+}
+
+func (c *Client) findMembership(username, groupName string) (*User, *Group, error) {
+	user, err := c.findUser(username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	group, err := c.findGroup(groupName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, group, nil
+}
+
+// Returns one page of items sorted by key. The fake's pagination token is the key the next page starts at.
+func paginate[T any](items []T, key func(T) string, limit *int32, token *string) ([]T, *string, error) {
+	size := int(aws.ToInt32(limit))
+	if size == 0 {
+		size = 60
+	}
+	if size < 1 || size > 60 {
+		return nil, nil, &types.InvalidParameterException{Message: aws.String("Limit must be between 1 and 60.")}
+	}
+
+	sort.Slice(items, func(i, j int) bool { return key(items[i]) < key(items[j]) })
+
+	start := 0
+	if token := aws.ToString(token); token != "" {
+		start = sort.Search(len(items), func(i int) bool { return key(items[i]) >= token })
+		if start == len(items) || key(items[start]) != token {
+			return nil, nil, &types.InvalidParameterException{Message: aws.String("Invalid pagination token.")}
+		}
+	}
+
+	end := min(start+size, len(items))
+	if end < len(items) {
+		return items[start:end], aws.String(key(items[end])), nil
+	}
+	return items[start:end], nil, nil
+}
+
+// Filter expressions accepted by ListUsers, e.g. email ^= "jane"
+var (
+	filterPattern = regexp.MustCompile(`^\s*([\w:]+)\s*(\^?=)\s*"((?:[^"\\]|\\.)*)"\s*$`)
+	filterEscape  = regexp.MustCompile(`\\(.)`)
+)
+
+// Attributes ListUsers can filter on
+var filterableAttributes = []string{
+	"username", "email", "phone_number", "name", "given_name", "family_name",
+	"preferred_username", "cognito:user_status", "status", "sub",
+}
+
+func parseFilter(filter string) (func(user *User) bool, error) {
+	if filter == "" {
+		return func(*User) bool { return true }, nil
+	}
+
+	matches := filterPattern.FindStringSubmatch(filter)
+	if matches == nil || !slices.Contains(filterableAttributes, matches[1]) {
+		return nil, &types.InvalidParameterException{Message: aws.String(fmt.Sprintf("Invalid filter expression: %s", filter))}
+	}
+
+	attribute, prefix := matches[1], matches[2] == "^="
+	value := filterEscape.ReplaceAllString(matches[3], "$1")
+
+	return func(user *User) bool {
+		var actual string
+		switch attribute {
+		case "username":
+			actual = user.Username
+		case "cognito:user_status":
+			actual = string(userStatus(user))
+		case "status":
+			actual = map[bool]string{true: "Disabled", false: "Enabled"}[user.Disabled]
+		default:
+			actual = user.Attributes[attribute]
+		}
+
+		if prefix {
+			return strings.HasPrefix(actual, value)
+		}
+		return actual == value
+	}, nil
+}
+
+// Returns the user as listed by the admin operations, attributes sorted by name
+func userType(user *User) *types.UserType {
+	names := make([]string, 0, len(user.Attributes))
+	for name := range user.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attributes := make([]types.AttributeType, 0, len(names))
+	for _, name := range names {
+		attributes = append(attributes, types.AttributeType{Name: aws.String(name), Value: aws.String(user.Attributes[name])})
+	}
+
+	return &types.UserType{
+		Username:             aws.String(user.Username),
+		Attributes:           attributes,
+		Enabled:              !user.Disabled,
+		UserStatus:           userStatus(user),
+		UserCreateDate:       aws.Time(user.CreatedAt),
+		UserLastModifiedDate: aws.Time(user.CreatedAt),
+	}
+}
+
+func userStatus(user *User) types.UserStatusType {
+	switch {
+	case !user.Confirmed:
+		return types.UserStatusTypeUnconfirmed
+	case user.PasswordResetRequired:
+		return types.UserStatusTypeResetRequired
+	case user.ForceChangePassword:
+		return types.UserStatusTypeForceChangePassword
+	}
+	return types.UserStatusTypeConfirmed
+}
+
+// Administrators may set the verified flags users cannot change themselves
+func isVerifiedFlag(name string) bool {
+	return name == "email_verified" || name == "phone_number_verified"
+}
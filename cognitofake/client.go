@@ -0,0 +1,854 @@
+// In-memory stand-in for the Cognito user pool API, used to exercise services and handlers without AWS
+
+package cognitofake
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"example.com/go-cognito/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Purposes for which a confirmation code is issued
+const (
+	CodeSignUp         = "signUp"
+	CodeForgotPassword = "forgotPassword"
+	CodeSMSMFA         = "smsMfa"
+	CodePasswordless   = "passwordless"
+	// Sent when a user changes or asks to verify their email address or phone number
+	CodeVerifyEmail       = "verifyEmail"
+	CodeVerifyPhoneNumber = "verifyPhoneNumber"
+)
+
+// Lifetime of issued access and ID tokens
+const TokenLifetime = time.Hour
+
+// A user stored in the fake user pool
+type User struct {
+	Username   string
+	Password   string
+	Attributes map[string]string
+	Confirmed  bool
+	// Set for users created by an administrator, who must choose a new password on first sign in
+	ForceChangePassword bool
+	// Base32 secret of the associated authenticator app, usable once verified
+	SoftwareTokenSecret   string
+	SoftwareTokenVerified bool
+	// MFA methods required at sign in, e.g. SOFTWARE_TOKEN_MFA, and the one challenged first
+	EnabledMFA   []string
+	PreferredMFA string
+	// Set by administrators, a disabled user cannot sign in and a reset one must confirm a new password first
+	Disabled              bool
+	PasswordResetRequired bool
+	// Names of the groups the user belongs to, added to the cognito:groups claim
+	Groups    []string
+	CreatedAt time.Time
+}
+
+// A group in the fake user pool
+type Group struct {
+	Name        string
+	Description string
+	Precedence  *int32
+	RoleArn     string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type Client struct {
+	ClientID     string
+	ClientSecret string
+	// Pool whose name is signed into SRP password claims, required for USER_SRP_AUTH
+	UserPoolID string
+
+	// Issues the access and ID tokens for a user, random opaque tokens are issued when nil
+	TokenIssuer func(user User) (accessToken, idToken string, err error)
+	// Called whenever a confirmation code is sent to a user
+	CodeSender func(user User, purpose, code string)
+
+	mu            sync.Mutex
+	users         map[string]*User
+	codes         map[string]string
+	accessTokens  map[string]string
+	refreshTokens map[string]string
+	sessions      map[string]*session
+	groups        map[string]*Group
+	failures      map[string]error
+}
+
+func New(clientId, clientSecret string) *Client {
+	return &Client{
+		ClientID:      clientId,
+		ClientSecret:  clientSecret,
+		users:         make(map[string]*User),
+		codes:         make(map[string]string),
+		accessTokens:  make(map[string]string),
+		refreshTokens: make(map[string]string),
+		sessions:      make(map[string]*session),
+		groups:        make(map[string]*Group),
+		failures:      make(map[string]error),
+	}
+}
+
+// Makes the next call to the named operation, e.g. "SignUp", return err
+func (c *Client) FailNext(operation string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures[operation] = err
+}
+
+// Adds a user to the pool, bypassing sign up
+func (c *Client) AddUser(user User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if user.Attributes == nil {
+		user.Attributes = make(map[string]string)
+	}
+	if user.Attributes["sub"] == "" {
+		user.Attributes["sub"] = randomHex(16)
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	c.users[user.Username] = &user
+}
+
+// Adds a group to the pool
+func (c *Client) AddGroup(group Group) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if group.CreatedAt.IsZero() {
+		group.CreatedAt = time.Now()
+	}
+	if group.UpdatedAt.IsZero() {
+		group.UpdatedAt = group.CreatedAt
+	}
+	c.groups[group.Name] = &group
+}
+
+// Returns a copy of a stored user
+func (c *Client) User(username string) (User, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	user, ok := c.users[username]
+	if !ok {
+		return User{}, false
+	}
+	return *user, true
+}
+
+// Returns the outstanding confirmation code sent to a user for the given purpose
+func (c *Client) Code(username, purpose string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	code, ok := c.codes[purpose+"/"+username]
+	return code, ok
+}
+
+func (c *Client) SignUp(ctx context.Context, params *cognitoidentityprovider.SignUpInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.SignUpOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	username := aws.ToString(params.Username)
+
+	if err := c.checkCall("SignUp", params.ClientId, params.SecretHash, username); err != nil {
+		return nil, err
+	}
+
+	if _, exists := c.users[username]; exists {
+		return nil, &types.UsernameExistsException{Message: aws.String("User already exists")}
+	}
+
+	if err := checkPasswordPolicy(aws.ToString(params.Password)); err != nil {
+		return nil, err
+	}
+
+	user := &User{
+		Username:   username,
+		Password:   aws.ToString(params.Password),
+		Attributes: map[string]string{"sub": randomHex(16)},
+		CreatedAt:  time.Now(),
+	}
+	for _, attribute := range params.UserAttributes {
+		user.Attributes[aws.ToString(attribute.Name)] = aws.ToString(attribute.Value)
+	}
+	c.users[username] = user
+
+	return &cognitoidentityprovider.SignUpOutput{
+		UserConfirmed:       false,
+		UserSub:             aws.String(user.Attributes["sub"]),
+		CodeDeliveryDetails: c.sendCode(user, CodeSignUp),
+	}, nil
+}
+
+func (c *Client) ConfirmSignUp(ctx context.Context, params *cognitoidentityprovider.ConfirmSignUpInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ConfirmSignUpOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	username := aws.ToString(params.Username)
+
+	if err := c.checkCall("ConfirmSignUp", params.ClientId, params.SecretHash, username); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.useCode(user, CodeSignUp, aws.ToString(params.ConfirmationCode)); err != nil {
+		return nil, err
+	}
+
+	user.Confirmed = true
+	// The code went to the email address, which proves it
+	if user.Attributes["email"] != "" {
+		user.Attributes["email_verified"] = "true"
+	}
+
+	return &cognitoidentityprovider.ConfirmSignUpOutput{}, nil
+}
+
+func (c *Client) InitiateAuth(ctx context.Context, params *cognitoidentityprovider.InitiateAuthInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.InitiateAuthOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	username := params.AuthParameters["USERNAME"]
+
+	switch params.AuthFlow {
+	case types.AuthFlowTypeUserPasswordAuth:
+		if err := c.checkCall("InitiateAuth", params.ClientId, aws.String(params.AuthParameters["SECRET_HASH"]), username); err != nil {
+			return nil, err
+		}
+
+		user, err := c.authenticate(username, params.AuthParameters["PASSWORD"])
+		if err != nil {
+			return nil, err
+		}
+
+		step, err := c.nextStep(user, "")
+		if err != nil {
+			return nil, err
+		}
+
+		return &cognitoidentityprovider.InitiateAuthOutput{
+			AuthenticationResult: step.result,
+			ChallengeName:        step.challengeName,
+			Session:              step.session,
+			ChallengeParameters:  step.challengeParameters,
+		}, nil
+	case types.AuthFlowTypeUserSrpAuth:
+		if err := c.checkCall("InitiateAuth", params.ClientId, aws.String(params.AuthParameters["SECRET_HASH"]), username); err != nil {
+			return nil, err
+		}
+
+		return c.startSRP(username, params.AuthParameters["SRP_A"])
+	case types.AuthFlowTypeCustomAuth:
+		if err := c.checkCall("InitiateAuth", params.ClientId, aws.String(params.AuthParameters["SECRET_HASH"]), username); err != nil {
+			return nil, err
+		}
+
+		user, ok := c.users[username]
+		if !ok {
+			return nil, &types.NotAuthorizedException{Message: aws.String("Incorrect username or password.")}
+		}
+		if err := checkCanSignIn(user); err != nil {
+			return nil, err
+		}
+
+		c.sendCode(user, CodePasswordless)
+		step := c.customChallenge(user, 0)
+
+		return &cognitoidentityprovider.InitiateAuthOutput{
+			ChallengeName:       step.challengeName,
+			Session:             step.session,
+			ChallengeParameters: step.challengeParameters,
+		}, nil
+	default:
+		return nil, &types.InvalidParameterException{Message: aws.String(fmt.Sprintf("Unsupported auth flow %s", params.AuthFlow))}
+	}
+}
+
+func (c *Client) RespondToAuthChallenge(ctx context.Context, params *cognitoidentityprovider.RespondToAuthChallengeInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.RespondToAuthChallengeOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	username := params.ChallengeResponses["USERNAME"]
+	if err := c.checkCall("RespondToAuthChallenge", params.ClientId, aws.String(params.ChallengeResponses["SECRET_HASH"]), username); err != nil {
+		return nil, err
+	}
+
+	user, state, err := c.useSession(aws.ToString(params.Session), username, params.ChallengeName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch params.ChallengeName {
+	case types.ChallengeNameTypePasswordVerifier:
+		if err := c.verifyPasswordClaim(user, state.srp, params.ChallengeResponses); err != nil {
+			return nil, err
+		}
+		if err := checkCanSignIn(user); err != nil {
+			return nil, err
+		}
+	case types.ChallengeNameTypeSoftwareTokenMfa:
+		if !validTOTP(user.SoftwareTokenSecret, params.ChallengeResponses["SOFTWARE_TOKEN_MFA_CODE"]) {
+			return nil, &types.CodeMismatchException{Message: aws.String("Invalid code received for user")}
+		}
+	case types.ChallengeNameTypeCustomChallenge:
+		// Mirrors the auth challenge triggers in infra/lambda, a wrong code gets another round
+		if params.ChallengeResponses["ANSWER"] != c.codes[CodePasswordless+"/"+user.Username] {
+			if state.attempts+1 >= maxCustomChallengeAttempts {
+				delete(c.codes, CodePasswordless+"/"+user.Username)
+				return nil, &types.NotAuthorizedException{Message: aws.String("Incorrect username or password.")}
+			}
+
+			step := c.customChallenge(user, state.attempts+1)
+			return &cognitoidentityprovider.RespondToAuthChallengeOutput{
+				ChallengeName:       step.challengeName,
+				Session:             step.session,
+				ChallengeParameters: step.challengeParameters,
+			}, nil
+		}
+		delete(c.codes, CodePasswordless+"/"+user.Username)
+	case types.ChallengeNameTypeSmsMfa:
+		if err := c.useCode(user, CodeSMSMFA, params.ChallengeResponses["SMS_MFA_CODE"]); err != nil {
+			return nil, err
+		}
+	case types.ChallengeNameTypeNewPasswordRequired:
+		newPassword := params.ChallengeResponses["NEW_PASSWORD"]
+		if err := checkPasswordPolicy(newPassword); err != nil {
+			return nil, err
+		}
+
+		user.Password = newPassword
+		user.ForceChangePassword = false
+		for key, value := range params.ChallengeResponses {
+			if name, ok := strings.CutPrefix(key, "userAttributes."); ok {
+				user.Attributes[name] = value
+			}
+		}
+	default:
+		return nil, &types.InvalidParameterException{Message: aws.String(fmt.Sprintf("Unsupported challenge %s", params.ChallengeName))}
+	}
+
+	step, err := c.nextStep(user, params.ChallengeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cognitoidentityprovider.RespondToAuthChallengeOutput{
+		AuthenticationResult: step.result,
+		ChallengeName:        step.challengeName,
+		Session:              step.session,
+		ChallengeParameters:  step.challengeParameters,
+	}, nil
+}
+
+func (c *Client) AssociateSoftwareToken(ctx context.Context, params *cognitoidentityprovider.AssociateSoftwareTokenInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AssociateSoftwareTokenOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("AssociateSoftwareToken"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	// A new association replaces the previous authenticator app
+	user.SoftwareTokenSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	user.SoftwareTokenVerified = false
+	user.EnabledMFA = without(user.EnabledMFA, string(types.ChallengeNameTypeSoftwareTokenMfa))
+
+	return &cognitoidentityprovider.AssociateSoftwareTokenOutput{SecretCode: aws.String(user.SoftwareTokenSecret)}, nil
+}
+
+func (c *Client) VerifySoftwareToken(ctx context.Context, params *cognitoidentityprovider.VerifySoftwareTokenInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.VerifySoftwareTokenOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("VerifySoftwareToken"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if user.SoftwareTokenSecret == "" {
+		return nil, &types.InvalidParameterException{Message: aws.String("User has not associated a software token")}
+	}
+
+	if !validTOTP(user.SoftwareTokenSecret, aws.ToString(params.UserCode)) {
+		return nil, &types.EnableSoftwareTokenMFAException{Message: aws.String("Code mismatch")}
+	}
+
+	user.SoftwareTokenVerified = true
+
+	return &cognitoidentityprovider.VerifySoftwareTokenOutput{Status: types.VerifySoftwareTokenResponseTypeSuccess}, nil
+}
+
+func (c *Client) SetUserMFAPreference(ctx context.Context, params *cognitoidentityprovider.SetUserMFAPreferenceInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.SetUserMFAPreferenceOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("SetUserMFAPreference"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if settings := params.SoftwareTokenMfaSettings; settings != nil {
+		method := string(types.ChallengeNameTypeSoftwareTokenMfa)
+		if settings.Enabled && !user.SoftwareTokenVerified {
+			return nil, &types.SoftwareTokenMFANotFoundException{Message: aws.String("User has not verified software token mfa")}
+		}
+		setMFA(user, method, settings.Enabled, settings.PreferredMfa)
+	}
+
+	if settings := params.SMSMfaSettings; settings != nil {
+		method := string(types.ChallengeNameTypeSmsMfa)
+		if settings.Enabled && user.Attributes["phone_number"] == "" {
+			return nil, &types.InvalidParameterException{Message: aws.String("User does not have delivery config set to turn on SMS_MFA")}
+		}
+		setMFA(user, method, settings.Enabled, settings.PreferredMfa)
+	}
+
+	return &cognitoidentityprovider.SetUserMFAPreferenceOutput{}, nil
+}
+
+func (c *Client) ForgotPassword(ctx context.Context, params *cognitoidentityprovider.ForgotPasswordInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ForgotPasswordOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	username := aws.ToString(params.Username)
+
+	if err := c.checkCall("ForgotPassword", params.ClientId, params.SecretHash, username); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cognitoidentityprovider.ForgotPasswordOutput{CodeDeliveryDetails: c.sendCode(user, CodeForgotPassword)}, nil
+}
+
+func (c *Client) ConfirmForgotPassword(ctx context.Context, params *cognitoidentityprovider.ConfirmForgotPasswordInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ConfirmForgotPasswordOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	username := aws.ToString(params.Username)
+
+	if err := c.checkCall("ConfirmForgotPassword", params.ClientId, params.SecretHash, username); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPasswordPolicy(aws.ToString(params.Password)); err != nil {
+		return nil, err
+	}
+
+	if err := c.useCode(user, CodeForgotPassword, aws.ToString(params.ConfirmationCode)); err != nil {
+		return nil, err
+	}
+
+	user.Password = aws.ToString(params.Password)
+	user.PasswordResetRequired = false
+
+	return &cognitoidentityprovider.ConfirmForgotPasswordOutput{}, nil
+}
+
+func (c *Client) ResendConfirmationCode(ctx context.Context, params *cognitoidentityprovider.ResendConfirmationCodeInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ResendConfirmationCodeOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	username := aws.ToString(params.Username)
+
+	if err := c.checkCall("ResendConfirmationCode", params.ClientId, params.SecretHash, username); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Confirmed {
+		return nil, &types.InvalidParameterException{Message: aws.String("User is already confirmed.")}
+	}
+
+	return &cognitoidentityprovider.ResendConfirmationCodeOutput{CodeDeliveryDetails: c.sendCode(user, CodeSignUp)}, nil
+}
+
+func (c *Client) GetTokensFromRefreshToken(ctx context.Context, params *cognitoidentityprovider.GetTokensFromRefreshTokenInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GetTokensFromRefreshTokenOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("GetTokensFromRefreshToken"); err != nil {
+		return nil, err
+	}
+
+	if aws.ToString(params.ClientId) != c.ClientID || aws.ToString(params.ClientSecret) != c.ClientSecret {
+		return nil, &types.NotAuthorizedException{Message: aws.String("Unable to verify secret hash for client")}
+	}
+
+	username, ok := c.refreshTokens[aws.ToString(params.RefreshToken)]
+	if !ok {
+		return nil, &types.NotAuthorizedException{Message: aws.String("Invalid Refresh Token")}
+	}
+	if c.users[username].Disabled {
+		return nil, &types.NotAuthorizedException{Message: aws.String("User is disabled.")}
+	}
+
+	result, err := c.issueTokens(c.users[username], false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cognitoidentityprovider.GetTokensFromRefreshTokenOutput{AuthenticationResult: result}, nil
+}
+
+func (c *Client) GlobalSignOut(ctx context.Context, params *cognitoidentityprovider.GlobalSignOutInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GlobalSignOutOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("GlobalSignOut"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	c.revokeTokens(user)
+
+	return &cognitoidentityprovider.GlobalSignOutOutput{}, nil
+}
+
+// Returns an injected failure, then validates the app client and secret hash like Cognito does
+func (c *Client) checkCall(operation string, clientId, secretHash *string, username string) error {
+	if err := c.takeFailure(operation); err != nil {
+		return err
+	}
+
+	if aws.ToString(clientId) != c.ClientID {
+		return &types.ResourceNotFoundException{Message: aws.String("User pool client does not exist.")}
+	}
+
+	if aws.ToString(secretHash) != utils.GetSecretHash(c.ClientID, c.ClientSecret, username) {
+		return &types.NotAuthorizedException{Message: aws.String(fmt.Sprintf("Client %s is configured with secret but SECRET_HASH was not received", c.ClientID))}
+	}
+
+	return nil
+}
+
+func (c *Client) takeFailure(operation string) error {
+	err := c.failures[operation]
+	delete(c.failures, operation)
+	return err
+}
+
+func (c *Client) findUser(username string) (*User, error) {
+	user, ok := c.users[username]
+	if !ok {
+		return nil, &types.UserNotFoundException{Message: aws.String("Username/client id combination not found.")}
+	}
+	return user, nil
+}
+
+func (c *Client) authenticate(username, password string) (*User, error) {
+	user, ok := c.users[username]
+	if !ok || user.Password != password {
+		return nil, &types.NotAuthorizedException{Message: aws.String("Incorrect username or password.")}
+	}
+
+	if err := checkCanSignIn(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Rejects users who proved their identity but may not sign in yet
+func checkCanSignIn(user *User) error {
+	switch {
+	case user.Disabled:
+		return &types.NotAuthorizedException{Message: aws.String("User is disabled.")}
+	case !user.Confirmed:
+		return &types.UserNotConfirmedException{Message: aws.String("User is not confirmed.")}
+	case user.PasswordResetRequired:
+		return &types.PasswordResetRequiredException{Message: aws.String("Password reset required for the user")}
+	}
+	return nil
+}
+
+// Outcome of a successful authentication step, either tokens or the next challenge
+type authStep struct {
+	result              *types.AuthenticationResultType
+	challengeName       types.ChallengeNameType
+	session             *string
+	challengeParameters map[string]string
+}
+
+// Decides what follows a completed step: a new password, then MFA, then tokens
+func (c *Client) nextStep(user *User, completed types.ChallengeNameType) (authStep, error) {
+	if user.ForceChangePassword {
+		return c.challenge(user, types.ChallengeNameTypeNewPasswordRequired, map[string]string{
+			"requiredAttributes": "[]",
+			"userAttributes":     "{}",
+		}), nil
+	}
+
+	// The custom flow decides on its own factors, so MFA is not added on top
+	if mfa := preferredMFA(user); mfa != "" && !isMFAChallenge(completed) && completed != types.ChallengeNameTypeCustomChallenge {
+		parameters := map[string]string{}
+		if mfa == types.ChallengeNameTypeSmsMfa {
+			delivery := c.sendCode(user, CodeSMSMFA)
+			parameters["CODE_DELIVERY_DELIVERY_MEDIUM"] = string(delivery.DeliveryMedium)
+			parameters["CODE_DELIVERY_DESTINATION"] = aws.ToString(delivery.Destination)
+		}
+		return c.challenge(user, mfa, parameters), nil
+	}
+
+	result, err := c.issueTokens(user, true)
+	if err != nil {
+		return authStep{}, err
+	}
+	return authStep{result: result}, nil
+}
+
+func (c *Client) challenge(user *User, name types.ChallengeNameType, parameters map[string]string) authStep {
+	parameters["USER_ID_FOR_SRP"] = user.Username
+	return authStep{
+		challengeName:       name,
+		session:             aws.String(c.startSession(&session{challengeName: name, username: user.Username})),
+		challengeParameters: parameters,
+	}
+}
+
+// Wrong codes allowed in the passwordless flow, as in the Define Auth Challenge trigger
+const maxCustomChallengeAttempts = 3
+
+// Presents the emailed code challenge, attempts counts the wrong answers so far
+func (c *Client) customChallenge(user *User, attempts int) authStep {
+	return authStep{
+		challengeName: types.ChallengeNameTypeCustomChallenge,
+		session: aws.String(c.startSession(&session{
+			challengeName: types.ChallengeNameTypeCustomChallenge,
+			username:      user.Username,
+			attempts:      attempts,
+		})),
+		challengeParameters: map[string]string{
+			"USERNAME": user.Username,
+			"email":    maskEmail(user.Attributes["email"]),
+		},
+	}
+}
+
+// An outstanding challenge, which can be answered once
+type session struct {
+	challengeName types.ChallengeNameType
+	username      string
+	srp           *srpState
+	attempts      int
+}
+
+func (c *Client) startSession(state *session) string {
+	id := randomHex(32)
+	c.sessions[id] = state
+	return id
+}
+
+func (c *Client) useSession(id, username string, challengeName types.ChallengeNameType) (*User, *session, error) {
+	state, ok := c.sessions[id]
+	if !ok || state.challengeName != challengeName || state.username != username {
+		return nil, nil, &types.NotAuthorizedException{Message: aws.String("Invalid session for the user, session is expired.")}
+	}
+	delete(c.sessions, id)
+
+	user, err := c.findUser(username)
+	return user, state, err
+}
+
+func (c *Client) userForAccessToken(accessToken string) (*User, error) {
+	username, ok := c.accessTokens[accessToken]
+	if !ok {
+		return nil, &types.NotAuthorizedException{Message: aws.String("Invalid Access Token")}
+	}
+	return c.users[username], nil
+}
+
+// Deletes a user along with everything issued to it
+func (c *Client) removeUser(user *User) {
+	delete(c.users, user.Username)
+	c.revokeTokens(user)
+
+	for key := range c.codes {
+		if strings.HasSuffix(key, "/"+user.Username) {
+			delete(c.codes, key)
+		}
+	}
+}
+
+func (c *Client) revokeTokens(user *User) {
+	for _, tokens := range []map[string]string{c.accessTokens, c.refreshTokens} {
+		for token, username := range tokens {
+			if username == user.Username {
+				delete(tokens, token)
+			}
+		}
+	}
+}
+
+func (c *Client) issueTokens(user *User, withRefreshToken bool) (*types.AuthenticationResultType, error) {
+	accessToken, idToken := randomHex(32), randomHex(32)
+
+	if c.TokenIssuer != nil {
+		var err error
+		if accessToken, idToken, err = c.TokenIssuer(*user); err != nil {
+			return nil, &types.InternalErrorException{Message: aws.String(fmt.Sprintf("Could not issue tokens: %v", err))}
+		}
+	}
+
+	result := &types.AuthenticationResultType{
+		AccessToken: aws.String(accessToken),
+		IdToken:     aws.String(idToken),
+		TokenType:   aws.String("Bearer"),
+		ExpiresIn:   int32(TokenLifetime.Seconds()),
+	}
+	c.accessTokens[accessToken] = user.Username
+
+	if withRefreshToken {
+		result.RefreshToken = aws.String(randomHex(32))
+		c.refreshTokens[*result.RefreshToken] = user.Username
+	}
+
+	return result, nil
+}
+
+func (c *Client) sendCode(user *User, purpose string) *types.CodeDeliveryDetailsType {
+	code, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		panic(err)
+	}
+	c.codes[purpose+"/"+user.Username] = fmt.Sprintf("%06d", code.Int64())
+
+	if c.CodeSender != nil {
+		c.CodeSender(*user, purpose, c.codes[purpose+"/"+user.Username])
+	}
+
+	// MFA and phone verification codes go to the phone, everything else to the email address
+	if purpose == CodeSMSMFA || purpose == CodeVerifyPhoneNumber {
+		return &types.CodeDeliveryDetailsType{
+			AttributeName:  aws.String("phone_number"),
+			DeliveryMedium: types.DeliveryMediumTypeSms,
+			Destination:    aws.String(maskPhoneNumber(user.Attributes["phone_number"])),
+		}
+	}
+
+	return &types.CodeDeliveryDetailsType{
+		AttributeName:  aws.String("email"),
+		DeliveryMedium: types.DeliveryMediumTypeEmail,
+		Destination:    aws.String(maskEmail(user.Attributes["email"])),
+	}
+}
+
+func (c *Client) useCode(user *User, purpose, code string) error {
+	key := purpose + "/" + user.Username
+
+	expected, ok := c.codes[key]
+	if !ok {
+		return &types.ExpiredCodeException{Message: aws.String("Invalid code provided, please request a code again.")}
+	}
+	if code != expected {
+		return &types.CodeMismatchException{Message: aws.String("Invalid verification code provided, please try again.")}
+	}
+
+	delete(c.codes, key)
+	return nil
+}
+
+// Applies the default Cognito password policy
+func checkPasswordPolicy(password string) error {
+	var violation string
+	switch {
+	case len(password) < 8:
+		violation = "Password not long enough"
+	case !strings.ContainsFunc(password, unicode.IsUpper):
+		violation = "Password must have uppercase characters"
+	case !strings.ContainsFunc(password, unicode.IsLower):
+		violation = "Password must have lowercase characters"
+	case !strings.ContainsFunc(password, unicode.IsDigit):
+		violation = "Password must have numeric characters"
+	case !strings.ContainsFunc(password, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) }):
+		violation = "Password must have symbol characters"
+	default:
+		return nil
+	}
+	return &types.InvalidPasswordException{Message: aws.String("Password did not conform with policy: " + violation)}
+}
+
+// Masks an email the way Cognito reports code destinations, e.g. j***@e***
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" || domain == "" {
+		return "***"
+	}
+	return firstRune(local) + "***@" + firstRune(domain) + "***"
+}
+
+func firstRune(s string) string {
+	_, size := utf8.DecodeRuneInString(s)
+	return s[:size]
+}
+
+// Keeps the last four digits, as Cognito does, e.g. +*******1234
+func maskPhoneNumber(phoneNumber string) string {
+	if len(phoneNumber) <= 4 {
+		return "***"
+	}
+	return "+" + strings.Repeat("*", len(phoneNumber)-5) + phoneNumber[len(phoneNumber)-4:]
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
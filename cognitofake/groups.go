@@ -0,0 +1,230 @@
+package cognitofake
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+func (c *Client) CreateGroup(ctx context.Context, params *cognitoidentityprovider.CreateGroupInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.CreateGroupOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("CreateGroup", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	name := aws.ToString(params.GroupName)
+	if _, exists := c.groups[name]; exists {
+		return nil, &types.GroupExistsException{Message: aws.String("A group with the name already exists.")}
+	}
+
+	if err := checkRoleArn(params.RoleArn); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	group := &Group{
+		Name:        name,
+		Description: aws.ToString(params.Description),
+		Precedence:  params.Precedence,
+		RoleArn:     aws.ToString(params.RoleArn),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	c.groups[name] = group
+
+	return &cognitoidentityprovider.CreateGroupOutput{Group: c.groupType(group)}, nil
+}
+
+func (c *Client) GetGroup(ctx context.Context, params *cognitoidentityprovider.GetGroupInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GetGroupOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("GetGroup", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	group, err := c.findGroup(aws.ToString(params.GroupName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &cognitoidentityprovider.GetGroupOutput{Group: c.groupType(group)}, nil
+}
+
+func (c *Client) UpdateGroup(ctx context.Context, params *cognitoidentityprovider.UpdateGroupInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.UpdateGroupOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("UpdateGroup", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	group, err := c.findGroup(aws.ToString(params.GroupName))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRoleArn(params.RoleArn); err != nil {
+		return nil, err
+	}
+
+	// Fields left out keep their value
+	if params.Description != nil {
+		group.Description = *params.Description
+	}
+	if params.Precedence != nil {
+		group.Precedence = params.Precedence
+	}
+	if params.RoleArn != nil {
+		group.RoleArn = *params.RoleArn
+	}
+	group.UpdatedAt = time.Now()
+
+	return &cognitoidentityprovider.UpdateGroupOutput{Group: c.groupType(group)}, nil
+}
+
+func (c *Client) DeleteGroup(ctx context.Context, params *cognitoidentityprovider.DeleteGroupInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.DeleteGroupOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("DeleteGroup", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	group, err := c.findGroup(aws.ToString(params.GroupName))
+	if err != nil {
+		return nil, err
+	}
+
+	delete(c.groups, group.Name)
+	for _, user := range c.users {
+		user.Groups = without(user.Groups, group.Name)
+	}
+
+	return &cognitoidentityprovider.DeleteGroupOutput{}, nil
+}
+
+func (c *Client) ListGroups(ctx context.Context, params *cognitoidentityprovider.ListGroupsInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListGroupsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("ListGroups", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	groups := make([]*Group, 0, len(c.groups))
+	for _, group := range c.groups {
+		groups = append(groups, group)
+	}
+
+	page, next, err := paginate(groups, func(group *Group) string { return group.Name }, params.Limit, params.NextToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cognitoidentityprovider.ListGroupsOutput{Groups: c.groupTypes(page), NextToken: next}, nil
+}
+
+func (c *Client) ListUsersInGroup(ctx context.Context, params *cognitoidentityprovider.ListUsersInGroupInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListUsersInGroupOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("ListUsersInGroup", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	group, err := c.findGroup(aws.ToString(params.GroupName))
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*User
+	for _, user := range c.users {
+		if slices.Contains(user.Groups, group.Name) {
+			members = append(members, user)
+		}
+	}
+
+	page, next, err := paginate(members, func(user *User) string { return user.Username }, params.Limit, params.NextToken)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &cognitoidentityprovider.ListUsersInGroupOutput{Users: []types.UserType{}, NextToken: next}
+	for _, user := range page {
+		output.Users = append(output.Users, *userType(user))
+	}
+
+	return output, nil
+}
+
+func (c *Client) AdminListGroupsForUser(ctx context.Context, params *cognitoidentityprovider.AdminListGroupsForUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminListGroupsForUserOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkAdminCall("AdminListGroupsForUser", params.UserPoolId); err != nil {
+		return nil, err
+	}
+
+	user, err := c.findUser(aws.ToString(params.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*Group, 0, len(user.Groups))
+	for _, name := range user.Groups {
+		if group, ok := c.groups[name]; ok {
+			groups = append(groups, group)
+		}
+	}
+
+	page, next, err := paginate(groups, func(group *Group) string { return group.Name }, params.Limit, params.NextToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cognitoidentityprovider.AdminListGroupsForUserOutput{Groups: c.groupTypes(page), NextToken: next}, nil
+}
+
+func (c *Client) groupType(group *Group) *types.GroupType {
+	groupType := &types.GroupType{
+		GroupName:        aws.String(group.Name),
+		UserPoolId:       aws.String(c.UserPoolID),
+		Precedence:       group.Precedence,
+		CreationDate:     aws.Time(group.CreatedAt),
+		LastModifiedDate: aws.Time(group.UpdatedAt),
+	}
+	if group.Description != "" {
+		groupType.Description = aws.String(group.Description)
+	}
+	if group.RoleArn != "" {
+		groupType.RoleArn = aws.String(group.RoleArn)
+	}
+	return groupType
+}
+
+func (c *Client) groupTypes(groups []*Group) []types.GroupType {
+	groupTypes := make([]types.GroupType, 0, len(groups))
+	for _, group := range groups {
+		groupTypes = append(groupTypes, *c.groupType(group))
+	}
+	return groupTypes
+}
+
+var iamRolePattern = regexp.MustCompile(`^arn:[\w+=/,.@-]+:iam::\d{12}:role/[\w+=/,.@-]+$`)
+
+// Cognito rejects role ARNs that are not IAM roles
+func checkRoleArn(roleArn *string) error {
+	if roleArn != nil && *roleArn != "" && !iamRolePattern.MatchString(*roleArn) {
+		return &types.InvalidParameterException{Message: aws.String(fmt.Sprintf("1 validation error detected: Value '%s' at 'roleArn' failed to satisfy constraint", *roleArn))}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package cognitofake
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Time step of the codes shown by authenticator apps
+const totpPeriod = 30 * time.Second
+
+// Returns the code an authenticator app shows at the given time for a base32 secret (RFC 6238)
+func TOTPCode(secret string, at time.Time) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(at.Unix()/int64(totpPeriod.Seconds())))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1000000), nil
+}
+
+// Accepts the current code and the ones either side of it to allow for clock drift
+func validTOTP(secret, code string) bool {
+	if secret == "" {
+		return false
+	}
+
+	now := time.Now()
+	for _, step := range []time.Duration{-totpPeriod, 0, totpPeriod} {
+		expected, err := TOTPCode(secret, now.Add(step))
+		if err == nil && subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the MFA challenge to send at sign in, if any
+func preferredMFA(user *User) types.ChallengeNameType {
+	if len(user.EnabledMFA) == 0 {
+		return ""
+	}
+	for _, method := range user.EnabledMFA {
+		if method == user.PreferredMFA {
+			return types.ChallengeNameType(method)
+		}
+	}
+	return types.ChallengeNameType(user.EnabledMFA[0])
+}
+
+func isMFAChallenge(name types.ChallengeNameType) bool {
+	return name == types.ChallengeNameTypeSoftwareTokenMfa || name == types.ChallengeNameTypeSmsMfa
+}
+
+func setMFA(user *User, method string, enabled, preferred bool) {
+	user.EnabledMFA = without(user.EnabledMFA, method)
+	if enabled {
+		user.EnabledMFA = append(user.EnabledMFA, method)
+	}
+
+	switch {
+	case enabled && preferred:
+		user.PreferredMFA = method
+	case user.PreferredMFA == method:
+		user.PreferredMFA = ""
+	}
+}
+
+func without(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
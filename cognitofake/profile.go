@@ -0,0 +1,235 @@
+package cognitofake
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Standard attributes of the user pool schema, custom attributes are accepted with a custom: prefix
+var standardAttributes = []string{
+	"address", "birthdate", "email", "email_verified", "family_name", "gender", "given_name",
+	"locale", "middle_name", "name", "nickname", "phone_number", "phone_number_verified",
+	"picture", "preferred_username", "profile", "sub", "updated_at", "website", "zoneinfo",
+}
+
+// Attributes Cognito manages itself, which users cannot write
+var readOnlyAttributes = []string{"sub", "email_verified", "phone_number_verified"}
+
+// Attributes that need a code sent to the new value before they count as verified
+var verificationCodes = map[string]string{
+	"email":        CodeVerifyEmail,
+	"phone_number": CodeVerifyPhoneNumber,
+}
+
+func (c *Client) GetUser(ctx context.Context, params *cognitoidentityprovider.GetUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GetUserOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("GetUser"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	output := &cognitoidentityprovider.GetUserOutput{
+		Username:           aws.String(user.Username),
+		UserAttributes:     userType(user).Attributes,
+		UserMFASettingList: slices.Clone(user.EnabledMFA),
+	}
+	if user.PreferredMFA != "" {
+		output.PreferredMfaSetting = aws.String(user.PreferredMFA)
+	}
+
+	return output, nil
+}
+
+func (c *Client) UpdateUserAttributes(ctx context.Context, params *cognitoidentityprovider.UpdateUserAttributesInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.UpdateUserAttributesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("UpdateUserAttributes"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate everything first so a rejected request changes nothing
+	for _, attribute := range params.UserAttributes {
+		if err := checkWritableAttribute(aws.ToString(attribute.Name)); err != nil {
+			return nil, err
+		}
+	}
+
+	output := &cognitoidentityprovider.UpdateUserAttributesOutput{}
+
+	for _, attribute := range params.UserAttributes {
+		name, value := aws.ToString(attribute.Name), aws.ToString(attribute.Value)
+		changed := user.Attributes[name] != value
+		user.Attributes[name] = value
+
+		// A new email or phone number stays unverified until its code is confirmed
+		if purpose, ok := verificationCodes[name]; ok && changed {
+			user.Attributes[name+"_verified"] = "false"
+			output.CodeDeliveryDetailsList = append(output.CodeDeliveryDetailsList, *c.sendCode(user, purpose))
+		}
+	}
+
+	return output, nil
+}
+
+func (c *Client) DeleteUserAttributes(ctx context.Context, params *cognitoidentityprovider.DeleteUserAttributesInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.DeleteUserAttributesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("DeleteUserAttributes"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range params.UserAttributeNames {
+		if err := checkWritableAttribute(name); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range params.UserAttributeNames {
+		delete(user.Attributes, name)
+		if purpose, ok := verificationCodes[name]; ok {
+			delete(user.Attributes, name+"_verified")
+			delete(c.codes, purpose+"/"+user.Username)
+		}
+	}
+
+	return &cognitoidentityprovider.DeleteUserAttributesOutput{}, nil
+}
+
+func (c *Client) DeleteUser(ctx context.Context, params *cognitoidentityprovider.DeleteUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.DeleteUserOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("DeleteUser"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	c.removeUser(user)
+
+	return &cognitoidentityprovider.DeleteUserOutput{}, nil
+}
+
+func (c *Client) VerifyUserAttribute(ctx context.Context, params *cognitoidentityprovider.VerifyUserAttributeInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.VerifyUserAttributeOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("VerifyUserAttribute"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	name := aws.ToString(params.AttributeName)
+	purpose, err := verificationPurpose(user, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.useCode(user, purpose, aws.ToString(params.Code)); err != nil {
+		return nil, err
+	}
+
+	user.Attributes[name+"_verified"] = "true"
+
+	return &cognitoidentityprovider.VerifyUserAttributeOutput{}, nil
+}
+
+func (c *Client) GetUserAttributeVerificationCode(ctx context.Context, params *cognitoidentityprovider.GetUserAttributeVerificationCodeInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GetUserAttributeVerificationCodeOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("GetUserAttributeVerificationCode"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	purpose, err := verificationPurpose(user, aws.ToString(params.AttributeName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &cognitoidentityprovider.GetUserAttributeVerificationCodeOutput{CodeDeliveryDetails: c.sendCode(user, purpose)}, nil
+}
+
+func (c *Client) ChangePassword(ctx context.Context, params *cognitoidentityprovider.ChangePasswordInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ChangePasswordOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.takeFailure("ChangePassword"); err != nil {
+		return nil, err
+	}
+
+	user, err := c.userForAccessToken(aws.ToString(params.AccessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.ToString(params.PreviousPassword) != user.Password {
+		return nil, &types.NotAuthorizedException{Message: aws.String("Incorrect username or password.")}
+	}
+
+	if err := checkPasswordPolicy(aws.ToString(params.ProposedPassword)); err != nil {
+		return nil, err
+	}
+
+	user.Password = aws.ToString(params.ProposedPassword)
+
+	return &cognitoidentityprovider.ChangePasswordOutput{}, nil
+}
+
+func checkWritableAttribute(name string) error {
+	switch {
+	case slices.Contains(readOnlyAttributes, name):
+		return &types.InvalidParameterException{Message: aws.String(fmt.Sprintf("Cannot modify the non-mutable attribute %s.", name))}
+	case !slices.Contains(standardAttributes, name) && !strings.HasPrefix(name, "custom:"):
+		return &types.InvalidParameterException{Message: aws.String(fmt.Sprintf("user.%s: Attribute does not exist in the schema.", name))}
+	}
+	return nil
+}
+
+// Returns the code purpose for an attribute the user has a value for
+func verificationPurpose(user *User, name string) (string, error) {
+	purpose, ok := verificationCodes[name]
+	if !ok {
+		return "", &types.InvalidParameterException{Message: aws.String(fmt.Sprintf("%s cannot be verified.", name))}
+	}
+	if user.Attributes[name] == "" {
+		return "", &types.InvalidParameterException{Message: aws.String(fmt.Sprintf("User has no %s.", name))}
+	}
+	return purpose, nil
+}
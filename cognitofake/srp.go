@@ -0,0 +1,125 @@
+package cognitofake
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"math/big"
+	"time"
+
+	"example.com/go-cognito/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Server side values kept between InitiateAuth and the PASSWORD_VERIFIER answer
+type srpState struct {
+	salt        string
+	secretBlock []byte
+	A, B, b     *big.Int
+}
+
+// Answers USER_SRP_AUTH with a PASSWORD_VERIFIER challenge
+func (c *Client) startSRP(username, srpA string) (*cognitoidentityprovider.InitiateAuthOutput, error) {
+	if c.UserPoolID == "" {
+		return nil, &types.InvalidParameterException{Message: aws.String("USER_SRP_AUTH requires the fake's UserPoolID to be set")}
+	}
+
+	n, g, k := utils.SRPGroup()
+
+	A, ok := new(big.Int).SetString(srpA, 16)
+	if !ok || new(big.Int).Mod(A, n).Sign() == 0 {
+		return nil, &types.InvalidParameterException{Message: aws.String("Invalid SRP_A")}
+	}
+
+	user, ok := c.users[username]
+	if !ok {
+		return nil, &types.NotAuthorizedException{Message: aws.String("Incorrect username or password.")}
+	}
+
+	state := &srpState{
+		salt:        randomHex(16),
+		secretBlock: randomBytes(64),
+		A:           A,
+		b:           new(big.Int).SetBytes(randomBytes(32)),
+	}
+
+	// B = k * v + g^b, with the verifier v derived from the stored password
+	v, err := c.srpVerifier(user, state.salt)
+	if err != nil {
+		return nil, err
+	}
+	state.B = new(big.Int).Mul(k, v)
+	state.B.Add(state.B, new(big.Int).Exp(g, state.b, n))
+	state.B.Mod(state.B, n)
+
+	session := c.startSession(&session{challengeName: types.ChallengeNameTypePasswordVerifier, username: user.Username, srp: state})
+
+	return &cognitoidentityprovider.InitiateAuthOutput{
+		ChallengeName: types.ChallengeNameTypePasswordVerifier,
+		Session:       aws.String(session),
+		ChallengeParameters: map[string]string{
+			"USER_ID_FOR_SRP": user.Username,
+			"SALT":            state.salt,
+			"SRP_B":           state.B.Text(16),
+			"SECRET_BLOCK":    base64.StdEncoding.EncodeToString(state.secretBlock),
+		},
+	}, nil
+}
+
+// Checks the signature the client derived from its copy of the shared secret
+func (c *Client) verifyPasswordClaim(user *User, state *srpState, responses map[string]string) error {
+	incorrect := &types.NotAuthorizedException{Message: aws.String("Incorrect username or password.")}
+
+	if state == nil {
+		return incorrect
+	}
+
+	timestamp := responses["TIMESTAMP"]
+	if _, err := time.Parse(utils.SRPTimestampLayout, timestamp); err != nil {
+		return &types.InvalidParameterException{Message: aws.String("TIMESTAMP format is invalid")}
+	}
+
+	if responses["PASSWORD_CLAIM_SECRET_BLOCK"] != base64.StdEncoding.EncodeToString(state.secretBlock) {
+		return incorrect
+	}
+
+	n, _, _ := utils.SRPGroup()
+
+	v, err := c.srpVerifier(user, state.salt)
+	if err != nil {
+		return err
+	}
+
+	// S = (A * v^u) ^ b
+	u := utils.SRPScramble(state.A, state.B)
+	S := new(big.Int).Exp(v, u, n)
+	S.Mul(S, state.A)
+	S.Exp(S, state.b, n)
+
+	expected := utils.SRPSignature(utils.SRPSessionKey(S, u), c.UserPoolID, user.Username, state.secretBlock, timestamp)
+	if !hmac.Equal([]byte(expected), []byte(responses["PASSWORD_CLAIM_SIGNATURE"])) {
+		return incorrect
+	}
+
+	return nil
+}
+
+func (c *Client) srpVerifier(user *User, salt string) (*big.Int, error) {
+	n, g, _ := utils.SRPGroup()
+
+	x, err := utils.SRPPrivateKey(c.UserPoolID, user.Username, user.Password, salt)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Exp(g, x, n), nil
+}
+
+func randomBytes(n int) []byte {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return buf
+}
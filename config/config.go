@@ -5,6 +5,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,12 +17,45 @@ type Config struct {
 	ClientSecret string
 	Region       string
 	UserPoolId   string
+	JWKSURL      string
+	// Additional app clients whose access tokens are accepted
+	AllowedClientIds []string
+	TokenClockSkew   time.Duration
+	// Token types accepted by protected routes, "access" and/or "id"
+	AcceptedTokenUses []string
+	// Issuer shown by authenticator apps, empty keeps the default
+	TOTPIssuer string
+	// Sign in flow, USER_PASSWORD_AUTH or USER_SRP_AUTH
+	AuthFlow string
+	// Attributes users may change at /me, empty keeps the default standard attributes
+	EditableAttributes []string
+	// Users per page of GET /admin/users when no limit is given, zero keeps the default
+	AdminPageSize int32
+	// Hosted UI base URL, enables /auth/authorize when set
+	HostedUIDomain   string
+	OAuthRedirectURI string
+	OAuthLogoutURI   string
+	// Scopes requested from the hosted UI, empty keeps openid, email and profile
+	OAuthScopes []string
+	// Serve requests from the in-memory Cognito emulator instead of AWS
+	LocalMode bool
 }
 
+// Placeholders used in local mode when the Cognito settings are not set
+const (
+	localClientId     = "local-client"
+	localClientSecret = "local-secret"
+	localRegion       = "us-east-1"
+	localUserPoolId   = "us-east-1_local"
+)
+
 func LoadConfig() *Config {
 	err := godotenv.Load()
 
-	if err != nil {
+	// A .env file is optional in local mode, which needs no AWS settings
+	localMode := os.Getenv("LOCAL_MODE") == "true"
+
+	if err != nil && !localMode {
 		log.Fatal("Could not retrieve environment variables.")
 	}
 
@@ -27,6 +63,67 @@ func LoadConfig() *Config {
 	clientSecret := os.Getenv("CLIENT_SECRET")
 	region := os.Getenv("REGION")
 	userPoolId := os.Getenv("USER_POOL_ID")
+	// Optional, defaults to the JWKS published by the user pool
+	jwksURL := os.Getenv("JWKS_URL")
+	// Optional, comma-separated list of other app clients sharing the user pool
+	allowedClientIds := splitList(os.Getenv("ALLOWED_CLIENT_IDS"))
+
+	// Optional, comma-separated, defaults to access tokens only
+	acceptedTokenUses := splitList(os.Getenv("ACCEPTED_TOKEN_USE"))
+	if len(acceptedTokenUses) == 0 {
+		acceptedTokenUses = []string{"access"}
+	}
+	for _, tokenUse := range acceptedTokenUses {
+		if tokenUse != "access" && tokenUse != "id" {
+			log.Fatalf("Invalid ACCEPTED_TOKEN_USE %q, expected access, id or both", tokenUse)
+		}
+	}
+
+	// Optional, name shown next to the account in authenticator apps
+	totpIssuer := os.Getenv("TOTP_ISSUER")
+
+	// Optional, defaults to USER_PASSWORD_AUTH
+	authFlow := valueOrDefault(os.Getenv("AUTH_FLOW"), "USER_PASSWORD_AUTH")
+	if authFlow != "USER_PASSWORD_AUTH" && authFlow != "USER_SRP_AUTH" {
+		log.Fatalf("Invalid AUTH_FLOW %q, expected USER_PASSWORD_AUTH or USER_SRP_AUTH", authFlow)
+	}
+
+	// Optional, comma-separated, e.g. "name,email,custom:team"
+	editableAttributes := splitList(os.Getenv("EDITABLE_ATTRIBUTES"))
+
+	// Optional, 1 to 60
+	var adminPageSize int32
+	if value := os.Getenv("ADMIN_PAGE_SIZE"); value != "" {
+		size, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || size < 1 || size > 60 {
+			log.Fatalf("Invalid ADMIN_PAGE_SIZE %q, expected a number from 1 to 60", value)
+		}
+		adminPageSize = int32(size)
+	}
+
+	// Optional, e.g. https://myapp.auth.us-east-1.amazoncognito.com
+	hostedUIDomain := os.Getenv("HOSTED_UI_DOMAIN")
+	// Optional, must match a callback and sign out URL of the app client
+	oauthRedirectURI := valueOrDefault(os.Getenv("OAUTH_REDIRECT_URI"), "http://localhost:8080/auth/callback")
+	oauthLogoutURI := valueOrDefault(os.Getenv("OAUTH_LOGOUT_URI"), "http://localhost:3000/")
+	// Optional, comma-separated, e.g. "openid,email,profile"
+	oauthScopes := splitList(os.Getenv("OAUTH_SCOPES"))
+
+	// Optional, e.g. "30s"
+	var tokenClockSkew time.Duration
+	if value := os.Getenv("TOKEN_CLOCK_SKEW"); value != "" {
+		tokenClockSkew, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid TOKEN_CLOCK_SKEW %q: %v", value, err)
+		}
+	}
+
+	if localMode {
+		clientId = valueOrDefault(clientId, localClientId)
+		clientSecret = valueOrDefault(clientSecret, localClientSecret)
+		region = valueOrDefault(region, localRegion)
+		userPoolId = valueOrDefault(userPoolId, localUserPoolId)
+	}
 
 	if clientId == "" || clientSecret == "" || userPoolId == "" {
 		log.Fatal("Could not retrieve Client ID, Client Secret, or User Pool ID.")
@@ -36,5 +133,40 @@ func LoadConfig() *Config {
 		ClientSecret: clientSecret,
 		Region:       region,
 		UserPoolId:   userPoolId,
+		JWKSURL:      jwksURL,
+
+		AllowedClientIds: allowedClientIds,
+		TokenClockSkew:   tokenClockSkew,
+
+		AcceptedTokenUses: acceptedTokenUses,
+		TOTPIssuer:        totpIssuer,
+		AuthFlow:          authFlow,
+		LocalMode:         localMode,
+
+		EditableAttributes: editableAttributes,
+		AdminPageSize:      adminPageSize,
+
+		HostedUIDomain:   hostedUIDomain,
+		OAuthRedirectURI: oauthRedirectURI,
+		OAuthLogoutURI:   oauthLogoutURI,
+		OAuthScopes:      oauthScopes,
+	}
+}
+
+func valueOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// Splits a comma-separated environment variable, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
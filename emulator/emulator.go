@@ -0,0 +1,162 @@
+// Local Cognito emulator, lets the server run without an AWS account
+
+package emulator
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"math/big"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"example.com/go-cognito/cognitofake"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// A confirmation code that would have been emailed to a user
+type SentCode struct {
+	Username string    `json:"username"`
+	Purpose  string    `json:"purpose"`
+	Code     string    `json:"code"`
+	SentAt   time.Time `json:"sentAt"`
+}
+
+// Cognito user pool backed by memory that issues RS256 tokens signed with a key generated at startup.
+// It embeds the in-memory fake, so it can be passed wherever a services.CognitoAPI is expected.
+type Emulator struct {
+	*cognitofake.Client
+
+	issuer string
+	keyId  string
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes []SentCode
+}
+
+// Creates an emulator for the given app client. Tokens carry the issuer the AuthService expects.
+func New(clientId, clientSecret, issuer string) (*Emulator, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	keyId := make([]byte, 8)
+	if _, err := rand.Read(keyId); err != nil {
+		return nil, err
+	}
+
+	emulator := &Emulator{
+		Client: cognitofake.New(clientId, clientSecret),
+		issuer: issuer,
+		keyId:  hex.EncodeToString(keyId),
+		key:    key,
+	}
+	// The issuer ends with the user pool ID, which SRP signatures cover
+	emulator.Client.UserPoolID = path.Base(issuer)
+	emulator.Client.TokenIssuer = emulator.issueTokens
+	emulator.Client.CodeSender = emulator.sendCode
+
+	return emulator, nil
+}
+
+// Serves the public signing key as a JWKS document
+func (e *Emulator) JWKS(context *gin.Context) {
+	context.JSON(http.StatusOK, gin.H{
+		"keys": []gin.H{{
+			"kty": "RSA",
+			"kid": e.keyId,
+			"alg": jwt.SigningMethodRS256.Alg(),
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(e.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(e.key.E)).Bytes()),
+		}},
+	})
+}
+
+// Lists the confirmation codes sent so far, newest last
+func (e *Emulator) Codes(context *gin.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	context.JSON(http.StatusOK, gin.H{"codes": append([]SentCode{}, e.codes...)})
+}
+
+func (e *Emulator) sendCode(user cognitofake.User, purpose, code string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	log.Printf("[emulator] %s code for %s: %s", purpose, user.Username, code)
+	e.codes = append(e.codes, SentCode{Username: user.Username, Purpose: purpose, Code: code, SentAt: time.Now()})
+}
+
+func (e *Emulator) issueTokens(user cognitofake.User) (string, string, error) {
+	now := time.Now()
+	expiresAt := now.Add(cognitofake.TokenLifetime)
+
+	accessClaims := jwt.MapClaims{
+		"iss":       e.issuer,
+		"sub":       user.Attributes["sub"],
+		"client_id": e.Client.ClientID,
+		"username":  user.Username,
+		"scope":     "aws.cognito.signin.user.admin",
+		"token_use": "access",
+		"auth_time": now.Unix(),
+		"iat":       now.Unix(),
+		"exp":       expiresAt.Unix(),
+		"jti":       randomHex(16),
+	}
+
+	idClaims := jwt.MapClaims{
+		"iss":              e.issuer,
+		"sub":              user.Attributes["sub"],
+		"aud":              e.Client.ClientID,
+		"cognito:username": user.Username,
+		"email":            user.Attributes["email"],
+		"email_verified":   user.Confirmed,
+		"name":             user.Attributes["name"],
+		"token_use":        "id",
+		"auth_time":        now.Unix(),
+		"iat":              now.Unix(),
+		"exp":              expiresAt.Unix(),
+		"jti":              randomHex(16),
+	}
+
+	// Like Cognito, the claim is left out for users without groups
+	if len(user.Groups) > 0 {
+		accessClaims["cognito:groups"] = user.Groups
+		idClaims["cognito:groups"] = user.Groups
+	}
+
+	accessToken, err := e.sign(accessClaims)
+	if err != nil {
+		return "", "", err
+	}
+
+	idToken, err := e.sign(idClaims)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, idToken, nil
+}
+
+func (e *Emulator) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = e.keyId
+	return token.SignedString(e.key)
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
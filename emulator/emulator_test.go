@@ -0,0 +1,115 @@
+package emulator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/services"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/gin-gonic/gin"
+)
+
+func TestEmulatorIssuesVerifiableTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	issuer := services.CognitoIssuer("us-east-1", "us-east-1_local")
+	emulator, err := New("local-client", "local-secret", issuer)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/jwks.json", emulator.JWKS)
+	router.GET("/codes", emulator.Codes)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	service := services.NewAuthService(emulator, "local-client", "local-secret", "us-east-1", "us-east-1_local")
+	service.JWKSURL = server.URL + "/jwks.json"
+	t.Cleanup(service.Close)
+
+	ctx := context.Background()
+	if err := service.SignUp(ctx, models.SignUpInput{UserName: "jane@example.com", Password: "Password-123", Name: "Jane"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	// The confirmation code is exposed on the dev endpoint instead of being emailed
+	response, err := http.Get(server.URL + "/codes")
+	if err != nil {
+		t.Fatalf("GET /codes: %v", err)
+	}
+	defer response.Body.Close()
+
+	var body struct {
+		Codes []SentCode `json:"codes"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatalf("decode codes: %v", err)
+	}
+	if len(body.Codes) != 1 || body.Codes[0].Username != "jane@example.com" {
+		t.Fatalf("unexpected codes: %+v", body.Codes)
+	}
+
+	if err := service.ConfirmAccount(ctx, models.UserConfirmationInput{Email: "jane@example.com", Code: body.Codes[0].Code}); err != nil {
+		t.Fatalf("ConfirmAccount() error = %v", err)
+	}
+
+	tokens, err := service.SignIn(ctx, models.SignInInput{UserName: "jane@example.com", Password: "Password-123"})
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+
+	accessClaims, err := service.VerifyToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("VerifyToken() error = %v", err)
+	}
+	if accessClaims.Username != "jane@example.com" || accessClaims.ClientID != "local-client" {
+		t.Fatalf("unexpected access claims: %+v", accessClaims)
+	}
+
+	idClaims, err := service.VerifyIDToken(tokens.IdToken, "")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if idClaims.Email != "jane@example.com" || idClaims.Name != "Jane" || idClaims.Subject != accessClaims.Subject {
+		t.Fatalf("unexpected ID claims: %+v", idClaims)
+	}
+
+	refreshed, err := service.GetTokensFromRefreshToken(ctx, models.RefreshTokenInput{RefreshToken: tokens.RefreshToken})
+	if err != nil {
+		t.Fatalf("GetTokensFromRefreshToken() error = %v", err)
+	}
+	if _, err := service.VerifyToken(*refreshed.AccessToken); err != nil {
+		t.Fatalf("VerifyToken() on refreshed token error = %v", err)
+	}
+
+	if _, err := service.SignOut(ctx, models.SignOutInput{AccessToken: tokens.AccessToken}); err != nil {
+		t.Fatalf("SignOut() error = %v", err)
+	}
+}
+
+func TestEmulatorSupportsSRP(t *testing.T) {
+	issuer := services.CognitoIssuer("us-east-1", "us-east-1_local")
+	emulator, err := New("local-client", "local-secret", issuer)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	emulator.AddUser(cognitofake.User{Username: "jane@example.com", Password: "Password-123", Confirmed: true})
+
+	service := services.NewAuthService(emulator, "local-client", "local-secret", "us-east-1", "us-east-1_local")
+	service.AuthFlow = types.AuthFlowTypeUserSrpAuth
+	t.Cleanup(service.Close)
+
+	tokens, err := service.SignIn(context.Background(), models.SignInInput{UserName: "jane@example.com", Password: "Password-123"})
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if tokens.AccessToken == "" {
+		t.Fatalf("unexpected response: %+v", tokens)
+	}
+}
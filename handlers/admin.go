@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"net/http"
+
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	Service *services.AdminService
+}
+
+func NewAdminHandler(service *services.AdminService) *AdminHandler {
+	return &AdminHandler{
+		Service: service,
+	}
+}
+
+// CreateUser godoc
+// @Summary      Create a user
+// @Description  Creates a confirmed user with a temporary password, emailed as an invitation unless suppressed. The user must choose a new password on first sign in.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user  body      models.AdminCreateUserInput  true  "New user"
+// @Success      201   {object}  models.User "Created user"
+// @Failure      400   {object}  models.ErrorResponse "Invalid input data or password policy violation"
+// @Failure      403   {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      409   {object}  models.ErrorResponse "User already exists"
+// @Router       /admin/users [post]
+func (h *AdminHandler) CreateUser(context *gin.Context) {
+	var input models.AdminCreateUserInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	user, err := h.Service.CreateUser(context, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusCreated, user)
+}
+
+// ListUsers godoc
+// @Summary      Search users
+// @Description  Lists users a page at a time, optionally filtered by one of email, name, username, phoneNumber or status. Pass nextToken from the previous page to continue.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        email        query     string   false  "Email address"
+// @Param        name         query     string   false  "Name"
+// @Param        username     query     string   false  "Username"
+// @Param        phoneNumber  query     string   false  "Phone number in E.164 format"
+// @Param        status       query     string   false  "User status, e.g. CONFIRMED or FORCE_CHANGE_PASSWORD"
+// @Param        prefix       query     bool     false  "Match values starting with the filter"
+// @Param        limit        query     int      false  "Page size, 1 to 60"
+// @Param        nextToken    query     string   false  "Token of the next page"
+// @Success      200          {object}  models.UserPage
+// @Failure      400          {object}  models.ErrorResponse "Invalid query, more than one filter or invalid token"
+// @Failure      403          {object}  models.ErrorResponse "Caller is not an admin"
+// @Router       /admin/users [get]
+func (h *AdminHandler) ListUsers(context *gin.Context) {
+	var input models.ListUsersInput
+
+	err := context.ShouldBindQuery(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	page, err := h.Service.ListUsers(context, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, page)
+}
+
+// GetUser godoc
+// @Summary      Get a user
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username  path      string  true  "Username"
+// @Success      200       {object}  models.User
+// @Failure      403       {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404       {object}  models.ErrorResponse "User not found"
+// @Router       /admin/users/{username} [get]
+func (h *AdminHandler) GetUser(context *gin.Context) {
+	user, err := h.Service.GetUser(context, context.Param("username"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, user)
+}
+
+// DeleteUser godoc
+// @Summary      Delete a user
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username  path      string  true  "Username"
+// @Success      200       "User deleted."
+// @Failure      403       {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404       {object}  models.ErrorResponse "User not found"
+// @Router       /admin/users/{username} [delete]
+func (h *AdminHandler) DeleteUser(context *gin.Context) {
+	err := h.Service.DeleteUser(context, context.Param("username"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "User deleted."})
+}
+
+// DisableUser godoc
+// @Summary      Disable a user
+// @Description  Blocks sign in and token refresh. Access tokens already issued stay valid until they expire.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username  path      string  true  "Username"
+// @Success      200       "User disabled."
+// @Failure      403       {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404       {object}  models.ErrorResponse "User not found"
+// @Router       /admin/users/{username}/disable [post]
+func (h *AdminHandler) DisableUser(context *gin.Context) {
+	err := h.Service.DisableUser(context, context.Param("username"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "User disabled."})
+}
+
+// EnableUser godoc
+// @Summary      Enable a user
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username  path      string  true  "Username"
+// @Success      200       "User enabled."
+// @Failure      403       {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404       {object}  models.ErrorResponse "User not found"
+// @Router       /admin/users/{username}/enable [post]
+func (h *AdminHandler) EnableUser(context *gin.Context) {
+	err := h.Service.EnableUser(context, context.Param("username"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "User enabled."})
+}
+
+// ResetUserPassword godoc
+// @Summary      Reset a user's password
+// @Description  Invalidates the password and emails a reset code. The user sets a new one at /auth/confirmForgotPassword.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username  path      string  true  "Username"
+// @Success      200       "Password reset code sent."
+// @Failure      400       {object}  models.ErrorResponse "User has no verified email"
+// @Failure      403       {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404       {object}  models.ErrorResponse "User not found"
+// @Router       /admin/users/{username}/resetPassword [post]
+func (h *AdminHandler) ResetUserPassword(context *gin.Context) {
+	err := h.Service.ResetUserPassword(context, context.Param("username"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Password reset code sent."})
+}
+
+// SetUserPassword godoc
+// @Summary      Set a user's password
+// @Description  Sets a permanent password, or a temporary one the user must replace on the next sign in.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username  path      string                       true  "Username"
+// @Param        password  body      models.AdminSetPasswordInput  true  "New password"
+// @Success      200       "Password set."
+// @Failure      400       {object}  models.ErrorResponse "Invalid input data or password policy violation"
+// @Failure      403       {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404       {object}  models.ErrorResponse "User not found"
+// @Router       /admin/users/{username}/password [put]
+func (h *AdminHandler) SetUserPassword(context *gin.Context) {
+	var input models.AdminSetPasswordInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	err = h.Service.SetUserPassword(context, context.Param("username"), input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Password set."})
+}
+
+// SignOutUser godoc
+// @Summary      Sign a user out everywhere
+// @Description  Revokes the user's refresh tokens on every device.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username  path      string  true  "Username"
+// @Success      200       "User signed out."
+// @Failure      403       {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404       {object}  models.ErrorResponse "User not found"
+// @Router       /admin/users/{username}/signOut [post]
+func (h *AdminHandler) SignOutUser(context *gin.Context) {
+	err := h.Service.SignOutUser(context, context.Param("username"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "User signed out."})
+}
+
+// AddUserToGroup godoc
+// @Summary      Add a user to a group
+// @Description  The membership appears in the cognito:groups claim of tokens issued afterwards.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username   path      string  true  "Username"
+// @Param        groupName  path      string  true  "Group name"
+// @Success      200        "User added to group."
+// @Failure      403        {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404        {object}  models.ErrorResponse "User or group not found"
+// @Router       /admin/users/{username}/groups/{groupName} [put]
+func (h *AdminHandler) AddUserToGroup(context *gin.Context) {
+	err := h.Service.AddUserToGroup(context, context.Param("username"), context.Param("groupName"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "User added to group."})
+}
+
+// RemoveUserFromGroup godoc
+// @Summary      Remove a user from a group
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username   path      string  true  "Username"
+// @Param        groupName  path      string  true  "Group name"
+// @Success      200        "User removed from group."
+// @Failure      403        {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404        {object}  models.ErrorResponse "User or group not found"
+// @Router       /admin/users/{username}/groups/{groupName} [delete]
+func (h *AdminHandler) RemoveUserFromGroup(context *gin.Context) {
+	err := h.Service.RemoveUserFromGroup(context, context.Param("username"), context.Param("groupName"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "User removed from group."})
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"github.com/gin-gonic/gin"
+)
+
+const adminUsername = "admin@example.com"
+
+// Adds a member of the admin group and returns its access token
+func (s *testServer) signInAdmin(t *testing.T) string {
+	t.Helper()
+
+	s.fake.AddGroup(cognitofake.Group{Name: "admin"})
+	s.fake.AddUser(cognitofake.User{
+		Username:   adminUsername,
+		Password:   testPassword,
+		Attributes: map[string]string{"email": adminUsername, "email_verified": "true"},
+		Confirmed:  true,
+		Groups:     []string{"admin"},
+	})
+
+	recorder := s.post(t, "/auth/signIn", gin.H{"username": adminUsername, "password": testPassword})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("admin signIn status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var response models.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return response.AccessToken
+}
+
+func TestAdminRequiresAdminGroup(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	server.signUpConfirmed(t)
+	accessToken := server.signIn(t).AccessToken
+
+	recorder := server.send(t, http.MethodGet, "/admin/users/"+testUsername, accessToken, nil)
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestAdminCreateUser(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	accessToken := server.signInAdmin(t)
+
+	recorder := server.send(t, http.MethodPost, "/admin/users", accessToken, gin.H{"username": "not-an-email"})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	recorder = server.send(t, http.MethodPost, "/admin/users", accessToken, gin.H{"username": testUsername, "name": "Jane", "temporaryPassword": "Temp-1234"})
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var user models.User
+	if err := json.Unmarshal(recorder.Body.Bytes(), &user); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if user.Username != testUsername || user.Status != "FORCE_CHANGE_PASSWORD" || !user.Enabled || !user.EmailVerified {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	recorder = server.send(t, http.MethodPost, "/admin/users", accessToken, gin.H{"username": testUsername, "name": "Jane"})
+	assertError(t, recorder, http.StatusConflict, CodeUsernameExists)
+
+	// The invited user must replace the temporary password
+	recorder = server.post(t, "/auth/signIn", gin.H{"username": testUsername, "password": "Temp-1234"})
+	var challenge models.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &challenge); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if challenge.ChallengeName != "NEW_PASSWORD_REQUIRED" {
+		t.Fatalf("unexpected sign in response: %s", recorder.Body)
+	}
+}
+
+func TestAdminManageUser(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	server.signUpConfirmed(t)
+	accessToken := server.signInAdmin(t)
+	path := "/admin/users/" + testUsername
+
+	recorder := server.send(t, http.MethodGet, "/admin/users/nobody@example.com", accessToken, nil)
+	assertError(t, recorder, http.StatusNotFound, CodeUserNotFound)
+
+	recorder = server.send(t, http.MethodGet, path, accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("get status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodPost, path+"/disable", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("disable status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	recorder = server.post(t, "/auth/signIn", gin.H{"username": testUsername, "password": testPassword})
+	assertError(t, recorder, http.StatusUnauthorized, CodeNotAuthorized)
+
+	recorder = server.send(t, http.MethodPost, path+"/enable", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("enable status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	server.signIn(t)
+
+	recorder = server.send(t, http.MethodPost, path+"/resetPassword", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("resetPassword status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	recorder = server.post(t, "/auth/signIn", gin.H{"username": testUsername, "password": testPassword})
+	assertError(t, recorder, http.StatusForbidden, CodePasswordResetRequired)
+
+	recorder = server.send(t, http.MethodPut, path+"/password", accessToken, gin.H{"password": "weak"})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidPassword)
+
+	recorder = server.send(t, http.MethodPut, path+"/password", accessToken, gin.H{"password": testPassword, "permanent": true})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("password status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	server.signIn(t)
+
+	recorder = server.send(t, http.MethodPost, path+"/signOut", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("signOut status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodDelete, path, accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	if _, ok := server.fake.User(testUsername); ok {
+		t.Fatal("user still exists after delete")
+	}
+}
+
+func TestAdminGroupMembership(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	server.signUpConfirmed(t)
+	accessToken := server.signInAdmin(t)
+	path := "/admin/users/" + testUsername + "/groups/"
+
+	recorder := server.send(t, http.MethodPut, path+"missing", accessToken, nil)
+	assertError(t, recorder, http.StatusNotFound, CodeGroupNotFound)
+
+	recorder = server.send(t, http.MethodPut, path+"admin", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("add status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	// Tokens issued after joining carry the group
+	recorder = server.send(t, http.MethodGet, "/admin/users/"+testUsername, server.signIn(t).AccessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("new admin status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodDelete, path+"admin", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("remove status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	if user, _ := server.fake.User(testUsername); len(user.Groups) != 0 {
+		t.Fatalf("Groups = %v, want none", user.Groups)
+	}
+}
+
+func TestAdminListUsers(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	server.signUpConfirmed(t)
+	accessToken := server.signInAdmin(t)
+
+	recorder := server.send(t, http.MethodGet, "/admin/users?limit=100", accessToken, nil)
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	recorder = server.send(t, http.MethodGet, "/admin/users?email=jane@example.com&name=Jane", accessToken, nil)
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidParameter)
+
+	recorder = server.send(t, http.MethodGet, "/admin/users?limit=1", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("list status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var page models.UserPage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(page.Users) != 1 || page.Users[0].Username != adminUsername || page.NextToken == "" {
+		t.Fatalf("unexpected first page: %s", recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodGet, "/admin/users?limit=1&nextToken="+page.NextToken, accessToken, nil)
+	page = models.UserPage{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(page.Users) != 1 || page.Users[0].Username != testUsername || page.NextToken != "" {
+		t.Fatalf("unexpected second page: %s", recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodGet, "/admin/users?email=jane&prefix=true", accessToken, nil)
+	page = models.UserPage{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(page.Users) != 1 || page.Users[0].Email != testUsername {
+		t.Fatalf("unexpected search result: %s", recorder.Body)
+	}
+}
+
+func TestAdminManageGroups(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	server.signUpConfirmed(t)
+	accessToken := server.signInAdmin(t)
+
+	recorder := server.send(t, http.MethodPost, "/admin/groups", accessToken, gin.H{"name": "support", "precedence": -1})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	recorder = server.send(t, http.MethodPost, "/admin/groups", accessToken, gin.H{"name": "support", "description": "Support staff", "precedence": 5})
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodPost, "/admin/groups", accessToken, gin.H{"name": "support"})
+	assertError(t, recorder, http.StatusConflict, CodeGroupExists)
+
+	recorder = server.send(t, http.MethodPatch, "/admin/groups/support", accessToken, gin.H{"roleArn": "arn:aws:iam::123456789012:role/support"})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var group models.Group
+	if err := json.Unmarshal(recorder.Body.Bytes(), &group); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if group.Description != "Support staff" || group.Precedence == nil || *group.Precedence != 5 || group.RoleArn == "" {
+		t.Fatalf("unexpected group: %s", recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodPut, "/admin/users/"+testUsername+"/groups/support", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("add status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodGet, "/admin/groups/support/users", accessToken, nil)
+	var members models.UserPage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &members); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(members.Users) != 1 || members.Users[0].Username != testUsername {
+		t.Fatalf("unexpected members: %s", recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodGet, "/admin/users/"+testUsername+"/groups", accessToken, nil)
+	var groups models.GroupPage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &groups); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(groups.Groups) != 1 || groups.Groups[0].Name != "support" {
+		t.Fatalf("unexpected groups: %s", recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodGet, "/admin/groups?limit=1", accessToken, nil)
+	groups = models.GroupPage{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &groups); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(groups.Groups) != 1 || groups.Groups[0].Name != "admin" || groups.NextToken == "" {
+		t.Fatalf("unexpected first page: %s", recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodDelete, "/admin/groups/support", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodGet, "/admin/groups/support", accessToken, nil)
+	assertError(t, recorder, http.StatusNotFound, CodeGroupNotFound)
+}
@@ -27,7 +27,8 @@ func NewAuthHandler(service *services.AuthService) *AuthHandler {
 // @Produce      json
 // @Param        user  body      models.SignUpInput  true  "Sign up data"
 // @Success      200   "Successfully signed up user!"
-// @Failure      400   "Invalid input data or signup failed".
+// @Failure      400   {object}  models.ErrorResponse "Invalid input data or password policy violation"
+// @Failure      409   {object}  models.ErrorResponse "User already exists"
 // @Router       /auth/signUp [post]
 func (h *AuthHandler) SignUp(context *gin.Context) {
 
@@ -35,14 +36,14 @@ func (h *AuthHandler) SignUp(context *gin.Context) {
 
 	err := context.ShouldBindJSON(&user)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data."})
+		respondWithInvalidInput(context)
 		return
 	}
 
 	err = h.Service.SignUp(context, user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		respondWithError(context, err)
 		return
 	}
 
@@ -56,8 +57,10 @@ func (h *AuthHandler) SignUp(context *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        user  body      models.SignInInput  true  "Sign in data"
-// @Success      200   {object}  models.AuthResponse "Successfully logged in user!"
-// @Failure      400   "Invalid input data or sign in failed".
+// @Success      200   {object}  models.AuthResponse "Successfully logged in user, or the challenge to answer next"
+// @Failure      400   {object}  models.ErrorResponse "Invalid input data"
+// @Failure      401   {object}  models.ErrorResponse "Incorrect username or password"
+// @Failure      403   {object}  models.ErrorResponse "User is not confirmed or must reset password"
 // @Router       /auth/signIn [post]
 func (h *AuthHandler) SignIn(context *gin.Context) {
 	var user models.SignInInput
@@ -65,14 +68,77 @@ func (h *AuthHandler) SignIn(context *gin.Context) {
 	err := context.ShouldBindJSON(&user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data."})
+		respondWithInvalidInput(context)
 		return
 	}
 
 	authResult, err := h.Service.SignIn(context, user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, authResult)
+}
+
+// StartPasswordlessSignIn godoc
+// @Summary      Start a passwordless sign in
+// @Description  Emails a one-time code and returns a CUSTOM_CHALLENGE. Answer it at respondToChallenge with the code.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        user  body      models.PasswordlessSignInInput  true  "User to sign in"
+// @Success      200   {object}  models.AuthResponse "CUSTOM_CHALLENGE to answer with the emailed code"
+// @Failure      400   {object}  models.ErrorResponse "Invalid input data"
+// @Failure      401   {object}  models.ErrorResponse "Unknown user"
+// @Failure      403   {object}  models.ErrorResponse "User is not confirmed"
+// @Router       /auth/startPasswordlessSignIn [post]
+func (h *AuthHandler) StartPasswordlessSignIn(context *gin.Context) {
+	var user models.PasswordlessSignInInput
+
+	err := context.ShouldBindJSON(&user)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	challenge, err := h.Service.StartPasswordlessSignIn(context, user)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, challenge)
+}
+
+// RespondToChallenge godoc
+// @Summary      Answer a sign in challenge
+// @Description  Answers a challenge returned by signIn or startPasswordlessSignIn, e.g. NEW_PASSWORD_REQUIRED. Returns tokens or the next challenge.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        challenge  body      models.RespondToChallengeInput  true  "Challenge answer"
+// @Success      200        {object}  models.AuthResponse "Successfully logged in user, or the challenge to answer next"
+// @Failure      400        {object}  models.ErrorResponse "Invalid input data, password or unsupported challenge"
+// @Failure      401        {object}  models.ErrorResponse "Session is invalid or expired"
+// @Router       /auth/respondToChallenge [post]
+func (h *AuthHandler) RespondToChallenge(context *gin.Context) {
+	var challenge models.RespondToChallengeInput
+
+	err := context.ShouldBindJSON(&challenge)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	authResult, err := h.Service.RespondToChallenge(context, challenge)
+
+	if err != nil {
+		respondWithError(context, err)
 		return
 	}
 
@@ -87,7 +153,7 @@ func (h *AuthHandler) SignIn(context *gin.Context) {
 // @Produce      json
 // @Param        user  body      models.UserConfirmationInput  true  "Confirmation code"
 // @Success      200   "Account confirmed."
-// @Failure      400   "Invalid input data".
+// @Failure      400   {object}  models.ErrorResponse "Invalid input data or confirmation code"
 // @Router       /auth/confirmAccount [post]
 func (h *AuthHandler) ConfirmAccount(context *gin.Context) {
 	var user models.UserConfirmationInput
@@ -95,14 +161,14 @@ func (h *AuthHandler) ConfirmAccount(context *gin.Context) {
 	err := context.ShouldBindJSON(&user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data."})
+		respondWithInvalidInput(context)
 		return
 	}
 
 	err = h.Service.ConfirmAccount(context, user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		respondWithError(context, err)
 		return
 	}
 
@@ -115,14 +181,14 @@ func (h *AuthHandler) ForgotPassword(context *gin.Context) {
 	err := context.ShouldBindJSON(&user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data."})
+		respondWithInvalidInput(context)
 		return
 	}
 
 	output, err := h.Service.ForgotPassword(context, user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		respondWithError(context, err)
 		return
 	}
 
@@ -135,14 +201,14 @@ func (h *AuthHandler) ConfirmForgotPassword(context *gin.Context) {
 	err := context.ShouldBindJSON(&user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data."})
+		respondWithInvalidInput(context)
 		return
 	}
 
 	err = h.Service.ConfirmForgotPassword(context, user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		respondWithError(context, err)
 		return
 	}
 
@@ -155,14 +221,14 @@ func (h *AuthHandler) ResendConfirmationCode(context *gin.Context) {
 	err := context.ShouldBindJSON(&user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data."})
+		respondWithInvalidInput(context)
 		return
 	}
 
 	output, err := h.Service.ResendConfirmationCode(context, user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		respondWithError(context, err)
 		return
 	}
 
@@ -175,7 +241,7 @@ func (h *AuthHandler) GetTokensFromRefreshToken(context *gin.Context) {
 	err := context.ShouldBindJSON(&user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data."})
+		respondWithInvalidInput(context)
 		return
 	}
 
@@ -184,7 +250,7 @@ func (h *AuthHandler) GetTokensFromRefreshToken(context *gin.Context) {
 	output, err := h.Service.GetTokensFromRefreshToken(context, user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		respondWithError(context, err)
 		return
 	}
 
@@ -197,7 +263,7 @@ func (h *AuthHandler) SignOut(context *gin.Context) {
 	err := context.ShouldBindJSON(&user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input data."})
+		respondWithInvalidInput(context)
 		return
 	}
 
@@ -206,7 +272,7 @@ func (h *AuthHandler) SignOut(context *gin.Context) {
 	output, err := h.Service.SignOut(context, user)
 
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		respondWithError(context, err)
 		return
 	}
 
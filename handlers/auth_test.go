@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testUsername = "jane@example.com"
+	testPassword = "Password-123"
+)
+
+type testServer struct {
+	router *gin.Engine
+	fake   *cognitofake.Client
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	fake := cognitofake.New("client-id", "client-secret")
+	service := services.NewAuthService(fake, "client-id", "client-secret", "us-east-1", "us-east-1_pool")
+	t.Cleanup(service.Close)
+
+	handler := NewAuthHandler(service)
+
+	router := gin.New()
+	router.POST("/auth/signUp", handler.SignUp)
+	router.POST("/auth/signIn", handler.SignIn)
+	router.POST("/auth/startPasswordlessSignIn", handler.StartPasswordlessSignIn)
+	router.POST("/auth/respondToChallenge", handler.RespondToChallenge)
+	router.POST("/auth/confirmAccount", handler.ConfirmAccount)
+	router.POST("/auth/forgotPassword", handler.ForgotPassword)
+	router.POST("/auth/confirmForgotPassword", handler.ConfirmForgotPassword)
+	router.POST("/auth/resendConfirmationCode", handler.ResendConfirmationCode)
+	router.POST("/auth/refreshToken", handler.GetTokensFromRefreshToken)
+	router.POST("/auth/signOut", handler.SignOut)
+
+	return &testServer{router: router, fake: fake}
+}
+
+func (s *testServer) post(t *testing.T, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload []byte
+	switch body := body.(type) {
+	case string:
+		payload = []byte(body)
+	default:
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *testServer) signUp(t *testing.T) {
+	t.Helper()
+
+	recorder := s.post(t, "/auth/signUp", gin.H{"username": testUsername, "password": testPassword, "name": "Jane"})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("signUp status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+}
+
+func (s *testServer) signUpConfirmed(t *testing.T) {
+	t.Helper()
+
+	s.signUp(t)
+	code, _ := s.fake.Code(testUsername, cognitofake.CodeSignUp)
+
+	recorder := s.post(t, "/auth/confirmAccount", gin.H{"email": testUsername, "code": code})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("confirmAccount status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+}
+
+func (s *testServer) signIn(t *testing.T) models.AuthResponse {
+	t.Helper()
+
+	recorder := s.post(t, "/auth/signIn", gin.H{"username": testUsername, "password": testPassword})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("signIn status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var response models.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return response
+}
+
+// Checks the status and error code of a failed request
+func assertError(t *testing.T, recorder *httptest.ResponseRecorder, wantStatus int, wantCode string) models.ErrorResponse {
+	t.Helper()
+
+	var body models.ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if recorder.Code != wantStatus || body.Code != wantCode {
+		t.Fatalf("got %d %+v, want %d %s", recorder.Code, body, wantStatus, wantCode)
+	}
+	return body
+}
+
+func TestHandlersRejectInvalidInput(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		path string
+		body any
+	}{
+		{"/auth/signUp", gin.H{"username": "not-an-email", "password": testPassword, "name": "Jane"}},
+		{"/auth/signIn", gin.H{"username": testUsername}},
+		{"/auth/startPasswordlessSignIn", gin.H{"username": "not-an-email"}},
+		{"/auth/respondToChallenge", gin.H{"username": testUsername, "challengeName": "NEW_PASSWORD_REQUIRED"}},
+		{"/auth/confirmAccount", gin.H{"email": testUsername}},
+		{"/auth/forgotPassword", "{"},
+		{"/auth/confirmForgotPassword", gin.H{"username": testUsername, "password": testPassword}},
+		{"/auth/resendConfirmationCode", gin.H{}},
+		{"/auth/refreshToken", gin.H{}},
+		{"/auth/signOut", gin.H{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assertError(t, server.post(t, tt.path, tt.body), http.StatusBadRequest, CodeInvalidInput)
+		})
+	}
+}
+
+func TestSignUp(t *testing.T) {
+	server := newTestServer(t)
+	server.signUp(t)
+
+	if _, ok := server.fake.User(testUsername); !ok {
+		t.Fatal("user was not created")
+	}
+
+	recorder := server.post(t, "/auth/signUp", gin.H{"username": testUsername, "password": testPassword, "name": "Jane"})
+	assertError(t, recorder, http.StatusConflict, CodeUsernameExists)
+
+	recorder = server.post(t, "/auth/signUp", gin.H{"username": "john@example.com", "password": "short", "name": "John"})
+	body := assertError(t, recorder, http.StatusBadRequest, CodeInvalidPassword)
+	if len(body.Details) == 0 {
+		t.Fatalf("expected password policy details, got %+v", body)
+	}
+}
+
+func TestSignIn(t *testing.T) {
+	server := newTestServer(t)
+	server.signUp(t)
+
+	recorder := server.post(t, "/auth/signIn", gin.H{"username": testUsername, "password": testPassword})
+	assertError(t, recorder, http.StatusForbidden, CodeUserNotConfirmed)
+
+	code, _ := server.fake.Code(testUsername, cognitofake.CodeSignUp)
+	server.post(t, "/auth/confirmAccount", gin.H{"email": testUsername, "code": code})
+
+	recorder = server.post(t, "/auth/signIn", gin.H{"username": testUsername, "password": "Wrong-Password-1"})
+	assertError(t, recorder, http.StatusUnauthorized, CodeNotAuthorized)
+
+	response := server.signIn(t)
+	if response.AccessToken == "" || response.IdToken == "" || response.RefreshToken == "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestRespondToChallenge(t *testing.T) {
+	server := newTestServer(t)
+	server.fake.AddUser(cognitofake.User{Username: testUsername, Password: "Temporary-1", Confirmed: true, ForceChangePassword: true})
+
+	recorder := server.post(t, "/auth/signIn", gin.H{"username": testUsername, "password": "Temporary-1"})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("signIn status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var challenge models.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &challenge); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if challenge.ChallengeName != "NEW_PASSWORD_REQUIRED" || challenge.Session == "" || challenge.AccessToken != "" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+
+	recorder = server.post(t, "/auth/respondToChallenge", gin.H{"username": testUsername, "challengeName": "NEW_PASSWORD_REQUIRED", "session": challenge.Session})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidParameter)
+
+	recorder = server.post(t, "/auth/respondToChallenge", gin.H{"username": testUsername, "challengeName": "SMS_MFA", "session": challenge.Session})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidParameter)
+
+	recorder = server.post(t, "/auth/respondToChallenge", gin.H{"username": testUsername, "challengeName": "NEW_PASSWORD_REQUIRED", "session": "invalid", "newPassword": testPassword})
+	assertError(t, recorder, http.StatusUnauthorized, CodeNotAuthorized)
+
+	recorder = server.post(t, "/auth/respondToChallenge", gin.H{"username": testUsername, "challengeName": "NEW_PASSWORD_REQUIRED", "session": challenge.Session, "newPassword": testPassword})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	response := server.signIn(t)
+	if response.AccessToken == "" || response.ChallengeName != "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestPasswordlessSignIn(t *testing.T) {
+	server := newTestServer(t)
+
+	recorder := server.post(t, "/auth/startPasswordlessSignIn", gin.H{"username": testUsername})
+	assertError(t, recorder, http.StatusUnauthorized, CodeNotAuthorized)
+
+	server.signUpConfirmed(t)
+
+	recorder = server.post(t, "/auth/startPasswordlessSignIn", gin.H{"username": testUsername})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var challenge models.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &challenge); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if challenge.ChallengeName != "CUSTOM_CHALLENGE" || challenge.ChallengeParameters["email"] == "" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+
+	code, _ := server.fake.Code(testUsername, cognitofake.CodePasswordless)
+	recorder = server.post(t, "/auth/respondToChallenge", gin.H{"username": testUsername, "challengeName": challenge.ChallengeName, "session": challenge.Session, "code": code})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var response models.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if response.AccessToken == "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestConfirmAccount(t *testing.T) {
+	server := newTestServer(t)
+	server.signUp(t)
+
+	recorder := server.post(t, "/auth/confirmAccount", gin.H{"email": testUsername, "code": "wrong"})
+	assertError(t, recorder, http.StatusBadRequest, CodeCodeMismatch)
+
+	recorder = server.post(t, "/auth/confirmAccount", gin.H{"email": "john@example.com", "code": "123456"})
+	assertError(t, recorder, http.StatusNotFound, CodeUserNotFound)
+
+	code, _ := server.fake.Code(testUsername, cognitofake.CodeSignUp)
+	recorder = server.post(t, "/auth/confirmAccount", gin.H{"email": testUsername, "code": code})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestForgotPassword(t *testing.T) {
+	server := newTestServer(t)
+	server.signUpConfirmed(t)
+
+	recorder := server.post(t, "/auth/forgotPassword", gin.H{"username": testUsername})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	if _, ok := server.fake.Code(testUsername, cognitofake.CodeForgotPassword); !ok {
+		t.Fatal("no reset code was sent")
+	}
+
+	recorder = server.post(t, "/auth/forgotPassword", gin.H{"username": "john@example.com"})
+	assertError(t, recorder, http.StatusNotFound, CodeUserNotFound)
+}
+
+func TestConfirmForgotPassword(t *testing.T) {
+	server := newTestServer(t)
+	server.signUpConfirmed(t)
+	server.post(t, "/auth/forgotPassword", gin.H{"username": testUsername})
+	code, _ := server.fake.Code(testUsername, cognitofake.CodeForgotPassword)
+
+	recorder := server.post(t, "/auth/confirmForgotPassword", gin.H{"username": testUsername, "password": "lowercase-1!", "code": code})
+	body := assertError(t, recorder, http.StatusBadRequest, CodeInvalidPassword)
+	if len(body.Details) != 1 || body.Details[0] != "Password must have uppercase characters" {
+		t.Fatalf("unexpected details: %+v", body)
+	}
+
+	recorder = server.post(t, "/auth/confirmForgotPassword", gin.H{"username": testUsername, "password": "New-Password-1", "code": code})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	if user, _ := server.fake.User(testUsername); user.Password != "New-Password-1" {
+		t.Fatal("password was not changed")
+	}
+}
+
+func TestResendConfirmationCode(t *testing.T) {
+	server := newTestServer(t)
+	server.signUp(t)
+
+	recorder := server.post(t, "/auth/resendConfirmationCode", gin.H{"username": testUsername})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	code, _ := server.fake.Code(testUsername, cognitofake.CodeSignUp)
+	server.post(t, "/auth/confirmAccount", gin.H{"email": testUsername, "code": code})
+
+	recorder = server.post(t, "/auth/resendConfirmationCode", gin.H{"username": testUsername})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidParameter)
+}
+
+func TestGetTokensFromRefreshToken(t *testing.T) {
+	server := newTestServer(t)
+	server.signUpConfirmed(t)
+	response := server.signIn(t)
+
+	recorder := server.post(t, "/auth/refreshToken", gin.H{"refreshToken": response.RefreshToken})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.post(t, "/auth/refreshToken", gin.H{"refreshToken": "invalid"})
+	assertError(t, recorder, http.StatusUnauthorized, CodeNotAuthorized)
+}
+
+func TestSignOut(t *testing.T) {
+	server := newTestServer(t)
+	server.signUpConfirmed(t)
+	response := server.signIn(t)
+
+	recorder := server.post(t, "/auth/signOut", gin.H{"accessToken": response.AccessToken})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.post(t, "/auth/signOut", gin.H{"accessToken": response.AccessToken})
+	assertError(t, recorder, http.StatusUnauthorized, CodeNotAuthorized)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Stable error codes returned in the code field of error responses
+const (
+	CodeInvalidInput          = "INVALID_INPUT"
+	CodeUserNotFound          = "USER_NOT_FOUND"
+	CodeUsernameExists        = "USERNAME_EXISTS"
+	CodeCodeMismatch          = "CODE_MISMATCH"
+	CodeExpiredCode           = "EXPIRED_CODE"
+	CodeNotAuthorized         = "NOT_AUTHORIZED"
+	CodeUserNotConfirmed      = "USER_NOT_CONFIRMED"
+	CodePasswordResetRequired = "PASSWORD_RESET_REQUIRED"
+	CodeLimitExceeded         = "LIMIT_EXCEEDED"
+	CodeTooManyRequests       = "TOO_MANY_REQUESTS"
+	CodeInvalidPassword       = "INVALID_PASSWORD"
+	CodeInvalidParameter      = "INVALID_PARAMETER"
+	CodeGroupNotFound         = "GROUP_NOT_FOUND"
+	CodeGroupExists           = "GROUP_EXISTS"
+	CodeInvalidState          = "INVALID_STATE"
+	CodeInternalError         = "INTERNAL_ERROR"
+)
+
+// HTTP status and code for each service error, checked in order with errors.Is
+var errorResponses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{services.ErrUserNotFound, http.StatusNotFound, CodeUserNotFound},
+	{services.ErrUsernameExists, http.StatusConflict, CodeUsernameExists},
+	{services.ErrCodeMismatch, http.StatusBadRequest, CodeCodeMismatch},
+	{services.ErrExpiredCode, http.StatusBadRequest, CodeExpiredCode},
+	{services.ErrNotAuthorized, http.StatusUnauthorized, CodeNotAuthorized},
+	{services.ErrUserNotConfirmed, http.StatusForbidden, CodeUserNotConfirmed},
+	{services.ErrPasswordResetRequired, http.StatusForbidden, CodePasswordResetRequired},
+	{services.ErrLimitExceeded, http.StatusTooManyRequests, CodeLimitExceeded},
+	{services.ErrTooManyRequests, http.StatusTooManyRequests, CodeTooManyRequests},
+	{services.ErrInvalidPassword, http.StatusBadRequest, CodeInvalidPassword},
+	{services.ErrInvalidParameter, http.StatusBadRequest, CodeInvalidParameter},
+	{services.ErrGroupNotFound, http.StatusNotFound, CodeGroupNotFound},
+	{services.ErrGroupExists, http.StatusConflict, CodeGroupExists},
+	{services.ErrInvalidState, http.StatusBadRequest, CodeInvalidState},
+}
+
+// Writes the error response matching a service error
+func respondWithError(context *gin.Context, err error) {
+	var details []string
+	var authErr *services.AuthError
+	if errors.As(err, &authErr) {
+		details = authErr.Details
+	}
+
+	for _, response := range errorResponses {
+		if errors.Is(err, response.err) {
+			context.JSON(response.status, models.ErrorResponse{Code: response.code, Message: err.Error(), Details: details})
+			return
+		}
+	}
+
+	// Unmapped errors carry AWS SDK details such as request IDs and ARNs, keep them in the server log
+	log.Printf("%s %s failed: %v", context.Request.Method, context.Request.URL.Path, err)
+	context.JSON(http.StatusInternalServerError, models.ErrorResponse{Code: CodeInternalError, Message: "Internal server error."})
+}
+
+// Writes the error response for a request body that failed to bind
+func respondWithInvalidInput(context *gin.Context) {
+	context.JSON(http.StatusBadRequest, models.ErrorResponse{Code: CodeInvalidInput, Message: "Invalid input data."})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/services"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestRespondWithError(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{&services.AuthError{Kind: services.ErrUserNotFound, Message: "User does not exist."}, http.StatusNotFound, CodeUserNotFound},
+		{&services.AuthError{Kind: services.ErrUsernameExists}, http.StatusConflict, CodeUsernameExists},
+		{&services.AuthError{Kind: services.ErrCodeMismatch}, http.StatusBadRequest, CodeCodeMismatch},
+		{&services.AuthError{Kind: services.ErrExpiredCode}, http.StatusBadRequest, CodeExpiredCode},
+		{&services.AuthError{Kind: services.ErrNotAuthorized}, http.StatusUnauthorized, CodeNotAuthorized},
+		{&services.AuthError{Kind: services.ErrUserNotConfirmed}, http.StatusForbidden, CodeUserNotConfirmed},
+		{&services.AuthError{Kind: services.ErrLimitExceeded}, http.StatusTooManyRequests, CodeLimitExceeded},
+		{&services.AuthError{Kind: services.ErrTooManyRequests}, http.StatusTooManyRequests, CodeTooManyRequests},
+		{&services.AuthError{Kind: services.ErrInvalidPassword}, http.StatusBadRequest, CodeInvalidPassword},
+		{services.ErrInvalidState, http.StatusBadRequest, CodeInvalidState},
+		{errors.New("operation error Cognito Identity Provider: AdminGetUser, RequestID: 1234"), http.StatusInternalServerError, CodeInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Request = httptest.NewRequest(http.MethodGet, "/me", nil)
+
+			respondWithError(context, tt.err)
+
+			var body models.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			// Mapped errors pass their message on, others are not shown to clients
+			wantMessage := tt.err.Error()
+			if tt.wantStatus == http.StatusInternalServerError {
+				wantMessage = "Internal server error."
+			}
+			if recorder.Code != tt.wantStatus || body.Code != tt.wantCode || body.Message != wantMessage {
+				t.Fatalf("got %d %+v, want %d %s", recorder.Code, body, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
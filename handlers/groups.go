@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"net/http"
+
+	"example.com/go-cognito/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateGroup godoc
+// @Summary      Create a group
+// @Description  Creates a group, optionally with a precedence and an IAM role for its members. Lower precedence values win when a user's groups have different roles.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        group  body      models.CreateGroupInput  true  "New group"
+// @Success      201    {object}  models.Group "Created group"
+// @Failure      400    {object}  models.ErrorResponse "Invalid input data or role ARN"
+// @Failure      403    {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      409    {object}  models.ErrorResponse "Group already exists"
+// @Router       /admin/groups [post]
+func (h *AdminHandler) CreateGroup(context *gin.Context) {
+	var input models.CreateGroupInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	group, err := h.Service.CreateGroup(context, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusCreated, group)
+}
+
+// ListGroups godoc
+// @Summary      List groups
+// @Description  Lists the groups of the user pool a page at a time. Pass nextToken from the previous page to continue.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit      query     int     false  "Page size, 1 to 60"
+// @Param        nextToken  query     string  false  "Token of the next page"
+// @Success      200        {object}  models.GroupPage
+// @Failure      400        {object}  models.ErrorResponse "Invalid query or token"
+// @Failure      403        {object}  models.ErrorResponse "Caller is not an admin"
+// @Router       /admin/groups [get]
+func (h *AdminHandler) ListGroups(context *gin.Context) {
+	var input models.PageInput
+
+	err := context.ShouldBindQuery(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	page, err := h.Service.ListGroups(context, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, page)
+}
+
+// GetGroup godoc
+// @Summary      Get a group
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        groupName  path      string  true  "Group name"
+// @Success      200        {object}  models.Group
+// @Failure      403        {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404        {object}  models.ErrorResponse "Group not found"
+// @Router       /admin/groups/{groupName} [get]
+func (h *AdminHandler) GetGroup(context *gin.Context) {
+	group, err := h.Service.GetGroup(context, context.Param("groupName"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, group)
+}
+
+// UpdateGroup godoc
+// @Summary      Update a group
+// @Description  Changes the description, precedence or role of a group. Fields left out are unchanged.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        groupName  path      string                   true  "Group name"
+// @Param        group      body      models.UpdateGroupInput  true  "Changes"
+// @Success      200        {object}  models.Group "Updated group"
+// @Failure      400        {object}  models.ErrorResponse "Invalid input data or role ARN"
+// @Failure      403        {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404        {object}  models.ErrorResponse "Group not found"
+// @Router       /admin/groups/{groupName} [patch]
+func (h *AdminHandler) UpdateGroup(context *gin.Context) {
+	var input models.UpdateGroupInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	group, err := h.Service.UpdateGroup(context, context.Param("groupName"), input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup godoc
+// @Summary      Delete a group
+// @Description  Removes the group from all its members. Tokens already issued keep the old cognito:groups claim until they expire.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        groupName  path      string  true  "Group name"
+// @Success      200        "Group deleted."
+// @Failure      403        {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404        {object}  models.ErrorResponse "Group not found"
+// @Router       /admin/groups/{groupName} [delete]
+func (h *AdminHandler) DeleteGroup(context *gin.Context) {
+	err := h.Service.DeleteGroup(context, context.Param("groupName"))
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Group deleted."})
+}
+
+// ListUsersInGroup godoc
+// @Summary      List members of a group
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        groupName  path      string  true   "Group name"
+// @Param        limit      query     int     false  "Page size, 1 to 60"
+// @Param        nextToken  query     string  false  "Token of the next page"
+// @Success      200        {object}  models.UserPage
+// @Failure      400        {object}  models.ErrorResponse "Invalid query or token"
+// @Failure      403        {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404        {object}  models.ErrorResponse "Group not found"
+// @Router       /admin/groups/{groupName}/users [get]
+func (h *AdminHandler) ListUsersInGroup(context *gin.Context) {
+	var input models.PageInput
+
+	err := context.ShouldBindQuery(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	page, err := h.Service.ListUsersInGroup(context, context.Param("groupName"), input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, page)
+}
+
+// ListGroupsForUser godoc
+// @Summary      List the groups of a user
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        username   path      string  true   "Username"
+// @Param        limit      query     int     false  "Page size, 1 to 60"
+// @Param        nextToken  query     string  false  "Token of the next page"
+// @Success      200        {object}  models.GroupPage
+// @Failure      400        {object}  models.ErrorResponse "Invalid query or token"
+// @Failure      403        {object}  models.ErrorResponse "Caller is not an admin"
+// @Failure      404        {object}  models.ErrorResponse "User not found"
+// @Router       /admin/users/{username}/groups [get]
+func (h *AdminHandler) ListGroupsForUser(context *gin.Context) {
+	var input models.PageInput
+
+	err := context.ShouldBindQuery(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	page, err := h.Service.ListGroupsForUser(context, context.Param("username"), input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, page)
+}
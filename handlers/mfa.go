@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+
+	"example.com/go-cognito/middleware"
+	"example.com/go-cognito/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AssociateSoftwareToken godoc
+// @Summary      Start authenticator app enrollment
+// @Description  Returns a secret and an otpauth:// URI for the signed in user. Confirm it with verifySoftwareToken before enabling it.
+// @Tags         MFA
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   {object}  models.SoftwareTokenResponse "Secret to add to an authenticator app"
+// @Failure      401   {object}  models.ErrorResponse "Missing or invalid access token"
+// @Router       /mfa/associateSoftwareToken [post]
+func (h *AuthHandler) AssociateSoftwareToken(context *gin.Context) {
+	accessToken, claims, ok := accessTokenFrom(context)
+	if !ok {
+		return
+	}
+
+	response, err := h.Service.AssociateSoftwareToken(context, accessToken, claims.Username)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, response)
+}
+
+// VerifySoftwareToken godoc
+// @Summary      Confirm authenticator app enrollment
+// @Description  Verifies a code from the authenticator app so the software token can be used for MFA.
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        code  body      models.VerifySoftwareTokenInput  true  "Code shown by the authenticator app"
+// @Success      200   "Software token verified."
+// @Failure      400   {object}  models.ErrorResponse "Invalid input data or code"
+// @Failure      401   {object}  models.ErrorResponse "Missing or invalid access token"
+// @Router       /mfa/verifySoftwareToken [post]
+func (h *AuthHandler) VerifySoftwareToken(context *gin.Context) {
+	accessToken, _, ok := accessTokenFrom(context)
+	if !ok {
+		return
+	}
+
+	var input models.VerifySoftwareTokenInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	err = h.Service.VerifySoftwareToken(context, accessToken, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Software token verified."})
+}
+
+// SetMFAPreference godoc
+// @Summary      Set MFA preference
+// @Description  Enables or disables MFA methods for the signed in user. Enabled methods are challenged at sign in.
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        preference  body      models.MFAPreferenceInput  true  "MFA settings"
+// @Success      200         "MFA preference updated."
+// @Failure      400         {object}  models.ErrorResponse "Invalid input data or method not verified"
+// @Failure      401         {object}  models.ErrorResponse "Missing or invalid access token"
+// @Router       /mfa/preference [put]
+func (h *AuthHandler) SetMFAPreference(context *gin.Context) {
+	accessToken, _, ok := accessTokenFrom(context)
+	if !ok {
+		return
+	}
+
+	var input models.MFAPreferenceInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	err = h.Service.SetUserMFAPreference(context, accessToken, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "MFA preference updated."})
+}
+
+// Returns the caller's access token, Cognito does not accept ID tokens for self-service calls
+func accessTokenFrom(context *gin.Context) (string, *models.AccessTokenClaims, bool) {
+	claims, hasClaims := middleware.ClaimsFrom(context)
+	token, hasToken := middleware.TokenFrom(context)
+
+	if !hasClaims || !hasToken {
+		context.JSON(http.StatusUnauthorized, models.ErrorResponse{Code: CodeNotAuthorized, Message: "An access token is required."})
+		return "", nil, false
+	}
+
+	return token, claims, true
+}
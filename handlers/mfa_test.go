@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSoftwareTokenMFA(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	server.signUpConfirmed(t)
+	accessToken := server.signIn(t).AccessToken
+
+	recorder := server.send(t, http.MethodPost, "/mfa/associateSoftwareToken", accessToken, gin.H{})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("associate status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var token models.SoftwareTokenResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &token); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if token.SecretCode == "" || token.OTPAuthURI == "" {
+		t.Fatalf("unexpected response: %+v", token)
+	}
+
+	recorder = server.send(t, http.MethodPut, "/mfa/preference", accessToken, gin.H{"softwareToken": gin.H{"enabled": true, "preferred": true}})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidParameter)
+
+	recorder = server.send(t, http.MethodPost, "/mfa/verifySoftwareToken", accessToken, gin.H{"code": "abc"})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	recorder = server.send(t, http.MethodPost, "/mfa/verifySoftwareToken", accessToken, gin.H{"code": "000000"})
+	assertError(t, recorder, http.StatusBadRequest, CodeCodeMismatch)
+
+	code, _ := cognitofake.TOTPCode(token.SecretCode, time.Now())
+	recorder = server.send(t, http.MethodPost, "/mfa/verifySoftwareToken", accessToken, gin.H{"code": code, "deviceName": "Phone"})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("verify status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodPut, "/mfa/preference", accessToken, gin.H{})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	recorder = server.send(t, http.MethodPut, "/mfa/preference", accessToken, gin.H{"softwareToken": gin.H{"enabled": true, "preferred": true}})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("preference status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	challenge := server.signIn(t)
+	if challenge.ChallengeName != "SOFTWARE_TOKEN_MFA" || challenge.Session == "" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+
+	code, _ = cognitofake.TOTPCode(token.SecretCode, time.Now())
+	recorder = server.post(t, "/auth/respondToChallenge", gin.H{"username": testUsername, "challengeName": challenge.ChallengeName, "session": challenge.Session, "code": code})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("respondToChallenge status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var response models.AuthResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if response.AccessToken == "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestSoftwareTokenMFARequiresAccessToken(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	server.signUpConfirmed(t)
+
+	recorder := server.send(t, http.MethodPost, "/mfa/associateSoftwareToken", "invalid", gin.H{})
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestSMSMFA(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+
+	recorder := server.post(t, "/auth/signUp", gin.H{"username": testUsername, "password": testPassword, "name": "Jane", "phoneNumber": "4155550100"})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	recorder = server.post(t, "/auth/signUp", gin.H{"username": testUsername, "password": testPassword, "name": "Jane", "phoneNumber": "+14155550100"})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("signUp status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	code, _ := server.fake.Code(testUsername, cognitofake.CodeSignUp)
+	server.post(t, "/auth/confirmAccount", gin.H{"email": testUsername, "code": code})
+
+	recorder = server.send(t, http.MethodPut, "/mfa/preference", server.signIn(t).AccessToken, gin.H{"sms": gin.H{"enabled": true}})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("preference status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	challenge := server.signIn(t)
+	if challenge.ChallengeName != "SMS_MFA" || challenge.CodeDeliveryDestination == "" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+
+	recorder = server.post(t, "/auth/respondToChallenge", gin.H{"username": testUsername, "challengeName": challenge.ChallengeName, "session": challenge.Session, "code": "000000"})
+	assertError(t, recorder, http.StatusBadRequest, CodeCodeMismatch)
+
+	challenge = server.signIn(t)
+	code, _ = server.fake.Code(testUsername, cognitofake.CodeSMSMFA)
+	recorder = server.post(t, "/auth/respondToChallenge", gin.H{"username": testUsername, "challengeName": challenge.ChallengeName, "session": challenge.Session, "code": code})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("respondToChallenge status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+}
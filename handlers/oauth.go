@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Keeps the state in the browser that started the sign in, only sent back to the callback
+const (
+	oauthStateCookie = "oauth_state"
+	oauthStatePath   = "/auth/callback"
+)
+
+type OAuthHandler struct {
+	Service *services.OAuthService
+}
+
+func NewOAuthHandler(service *services.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		Service: service,
+	}
+}
+
+// Authorize godoc
+// @Summary      Sign in with the hosted UI
+// @Description  Redirects to the Cognito hosted UI using the authorization code flow with PKCE. Pass provider, e.g. Google, to go straight to a social identity provider. The hosted UI returns to /auth/callback.
+// @Tags         OAuth
+// @Param        provider  query     string  false  "Identity provider name, e.g. Google"
+// @Success      302       "Redirect to the hosted UI"
+// @Failure      500       {object}  models.ErrorResponse "Could not start the sign in"
+// @Router       /auth/authorize [get]
+func (h *OAuthHandler) Authorize(context *gin.Context) {
+	var input models.AuthorizeInput
+
+	err := context.ShouldBindQuery(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	authorizeURL, state, err := h.Service.AuthorizeURL(input.Provider)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	// Lax so the cookie comes back on the hosted UI's top-level redirect
+	context.SetSameSite(http.SameSiteLaxMode)
+	context.SetCookie(oauthStateCookie, state, int(services.AuthorizationTimeout.Seconds()), oauthStatePath, "", true, true)
+
+	context.Redirect(http.StatusFound, authorizeURL)
+}
+
+// Callback godoc
+// @Summary      Finish a hosted UI sign in
+// @Description  Exchanges the authorization code the hosted UI redirected back with for tokens. Each state can be used once, expires after 10 minutes and is only accepted from the browser that started the sign in, which keeps it in the oauth_state cookie.
+// @Tags         OAuth
+// @Produce      json
+// @Param        code               query     string  false  "Authorization code"
+// @Param        state              query     string  true   "State sent to the hosted UI"
+// @Param        error              query     string  false  "Error code when the sign in failed"
+// @Param        error_description  query     string  false  "Error description"
+// @Success      200                {object}  models.AuthResponse
+// @Failure      400                {object}  models.ErrorResponse "Invalid input data, unknown state or missing state cookie"
+// @Failure      401                {object}  models.ErrorResponse "Sign in was denied or the code is invalid"
+// @Router       /auth/callback [get]
+func (h *OAuthHandler) Callback(context *gin.Context) {
+	var input models.OAuthCallbackInput
+
+	err := context.ShouldBindQuery(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	// Missing when the sign in was started in another browser
+	input.BrowserState, _ = context.Cookie(oauthStateCookie)
+	context.SetSameSite(http.SameSiteLaxMode)
+	context.SetCookie(oauthStateCookie, "", -1, oauthStatePath, "", true, true)
+
+	authResult, err := h.Service.HandleCallback(context, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, authResult)
+}
+
+// Logout godoc
+// @Summary      Sign out of the hosted UI
+// @Description  Redirects to the hosted UI logout endpoint, which clears its session cookie and returns to the configured sign out URL. Revoke the refresh token separately at /auth/signOut.
+// @Tags         OAuth
+// @Success      302  "Redirect to the hosted UI logout endpoint"
+// @Router       /auth/logout [get]
+func (h *OAuthHandler) Logout(context *gin.Context) {
+	context.Redirect(http.StatusFound, h.Service.LogoutURL())
+}
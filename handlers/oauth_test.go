@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"example.com/go-cognito/services"
+)
+
+const testHostedUIDomain = "https://example.auth.us-east-1.amazoncognito.com"
+
+func newOAuthTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	server := newTestServer(t)
+	service := services.NewOAuthService(services.NewAuthService(server.fake, "client-id", "client-secret", "us-east-1", "us-east-1_pool"), testHostedUIDomain, "http://localhost:8080/auth/callback")
+	service.LogoutURI = "http://localhost:3000/"
+	handler := NewOAuthHandler(service)
+
+	server.router.GET("/auth/authorize", handler.Authorize)
+	server.router.GET("/auth/callback", handler.Callback)
+	server.router.GET("/auth/logout", handler.Logout)
+
+	return server
+}
+
+// Sends a callback request carrying the state cookie set by /auth/authorize
+func (s *testServer) sendCallback(t *testing.T, query string, stateCookie *http.Cookie) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/auth/callback?"+query, nil)
+	if stateCookie != nil {
+		request.AddCookie(stateCookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func stateCookie(recorder *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == oauthStateCookie {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestAuthorizeRedirectsToHostedUI(t *testing.T) {
+	server := newOAuthTestServer(t)
+
+	recorder := server.send(t, http.MethodGet, "/auth/authorize?provider=Google", "", nil)
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	location, err := url.Parse(recorder.Header().Get("Location"))
+	if err != nil || !strings.HasPrefix(location.String(), testHostedUIDomain+"/oauth2/authorize?") {
+		t.Fatalf("Location = %s", recorder.Header().Get("Location"))
+	}
+	query := location.Query()
+	if query.Get("identity_provider") != "Google" || query.Get("state") == "" || query.Get("code_challenge") == "" {
+		t.Fatalf("unexpected query: %v", query)
+	}
+
+	cookie := stateCookie(recorder)
+	if cookie == nil || cookie.Value != query.Get("state") || !cookie.HttpOnly || !cookie.Secure ||
+		cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/auth/callback" || cookie.MaxAge != 600 {
+		t.Fatalf("unexpected state cookie: %+v", cookie)
+	}
+}
+
+func TestCallbackErrors(t *testing.T) {
+	server := newOAuthTestServer(t)
+
+	recorder := server.send(t, http.MethodGet, "/auth/callback?code=abc", "", nil)
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	recorder = server.send(t, http.MethodGet, "/auth/callback?code=abc&state=unknown", "", nil)
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidState)
+
+	authorize := server.send(t, http.MethodGet, "/auth/authorize", "", nil)
+	location, _ := url.Parse(authorize.Header().Get("Location"))
+	state := location.Query().Get("state")
+
+	// A callback URL opened in a browser that did not start the sign in, e.g. sent to a victim
+	recorder = server.sendCallback(t, "code=abc&state="+state, nil)
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidState)
+	recorder = server.sendCallback(t, "code=abc&state="+state, &http.Cookie{Name: oauthStateCookie, Value: "other-state"})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidState)
+
+	// The state of a sign in started in this browser is accepted, the hosted UI error is passed on
+	recorder = server.sendCallback(t, "error=access_denied&state="+state, stateCookie(authorize))
+	assertError(t, recorder, http.StatusUnauthorized, CodeNotAuthorized)
+	if cookie := stateCookie(recorder); cookie == nil || cookie.MaxAge >= 0 {
+		t.Fatalf("state cookie was not cleared: %+v", cookie)
+	}
+}
+
+func TestLogoutRedirectsToHostedUI(t *testing.T) {
+	server := newOAuthTestServer(t)
+
+	recorder := server.send(t, http.MethodGet, "/auth/logout", "", nil)
+	want := testHostedUIDomain + "/logout?client_id=client-id&logout_uri=http%3A%2F%2Flocalhost%3A3000%2F"
+	if recorder.Code != http.StatusFound || recorder.Header().Get("Location") != want {
+		t.Fatalf("got %d %s, want redirect to %s", recorder.Code, recorder.Header().Get("Location"), want)
+	}
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"net/http"
+
+	"example.com/go-cognito/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetProfile godoc
+// @Summary      Get the signed in user's profile
+// @Description  Returns the username, attributes and MFA settings stored in Cognito.
+// @Tags         Profile
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   {object}  models.UserProfile "Profile of the signed in user"
+// @Failure      401   {object}  models.ErrorResponse "Missing or invalid access token"
+// @Router       /me [get]
+func (h *AuthHandler) GetProfile(context *gin.Context) {
+	accessToken, _, ok := accessTokenFrom(context)
+	if !ok {
+		return
+	}
+
+	profile, err := h.Service.GetProfile(context, accessToken)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile godoc
+// @Summary      Update the signed in user's profile
+// @Description  Sets and deletes attributes. A new email or phone number stays unverified until the code sent to it is confirmed at /me/verifyAttribute.
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        profile  body      models.UpdateProfileInput  true  "Attribute changes"
+// @Success      200      "Profile updated, with where any verification codes were sent"
+// @Failure      400      {object}  models.ErrorResponse "Invalid input data or attribute that cannot be changed"
+// @Failure      401      {object}  models.ErrorResponse "Missing or invalid access token"
+// @Failure      409      {object}  models.ErrorResponse "Email or phone number already in use"
+// @Router       /me [patch]
+func (h *AuthHandler) UpdateProfile(context *gin.Context) {
+	accessToken, _, ok := accessTokenFrom(context)
+	if !ok {
+		return
+	}
+
+	var input models.UpdateProfileInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	codeDeliveryDetails, err := h.Service.UpdateProfile(context, accessToken, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Profile updated.", "codeDeliveryDetails": codeDeliveryDetails})
+}
+
+// DeleteProfile godoc
+// @Summary      Delete the signed in user's account
+// @Description  Permanently deletes the user from the user pool, its tokens stop working.
+// @Tags         Profile
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   "Account deleted."
+// @Failure      401   {object}  models.ErrorResponse "Missing or invalid access token"
+// @Router       /me [delete]
+func (h *AuthHandler) DeleteProfile(context *gin.Context) {
+	accessToken, _, ok := accessTokenFrom(context)
+	if !ok {
+		return
+	}
+
+	err := h.Service.DeleteAccount(context, accessToken)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Account deleted."})
+}
+
+// ChangePassword godoc
+// @Summary      Change the signed in user's password
+// @Description  Replaces the password after checking the previous one. With signOutOtherSessions every session is revoked and new tokens are returned for this one, unless signing out fails or signing in again fails or needs an MFA challenge answered, in which case the caller must sign in with the new password.
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        password  body      models.ChangePasswordInput  true  "Previous and proposed password"
+// @Success      200       "Password changed, with new tokens when other sessions were signed out"
+// @Failure      400       {object}  models.ErrorResponse "Invalid input data or password policy violation"
+// @Failure      401       {object}  models.ErrorResponse "Missing or invalid access token, or incorrect previous password"
+// @Failure      429       {object}  models.ErrorResponse "Too many attempts"
+// @Router       /me/password [post]
+func (h *AuthHandler) ChangePassword(context *gin.Context) {
+	accessToken, claims, ok := accessTokenFrom(context)
+	if !ok {
+		return
+	}
+
+	var input models.ChangePasswordInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	authResult, err := h.Service.ChangePassword(context, accessToken, claims.Username, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	if authResult != nil {
+		context.JSON(http.StatusOK, gin.H{"message": "Password changed.", "authResult": authResult})
+		return
+	}
+
+	if input.SignOutOtherSessions {
+		context.JSON(http.StatusOK, gin.H{"message": "Password changed, please sign in again."})
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Password changed."})
+}
+
+// VerifyAttribute godoc
+// @Summary      Verify an email or phone number
+// @Description  Confirms a changed email address or phone number with the code sent to it.
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        verification  body      models.VerifyAttributeInput  true  "Attribute and code"
+// @Success      200           "Attribute verified."
+// @Failure      400           {object}  models.ErrorResponse "Invalid input data, or invalid or expired code"
+// @Failure      401           {object}  models.ErrorResponse "Missing or invalid access token"
+// @Router       /me/verifyAttribute [post]
+func (h *AuthHandler) VerifyAttribute(context *gin.Context) {
+	accessToken, _, ok := accessTokenFrom(context)
+	if !ok {
+		return
+	}
+
+	var input models.VerifyAttributeInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	err = h.Service.VerifyAttribute(context, accessToken, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Attribute verified."})
+}
+
+// ResendAttributeVerificationCode godoc
+// @Summary      Resend an attribute verification code
+// @Description  Sends a new code to the signed in user's email address or phone number.
+// @Tags         Profile
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        attribute  body      models.AttributeVerificationCodeInput  true  "Attribute to verify"
+// @Success      200        "Where the code was sent"
+// @Failure      400        {object}  models.ErrorResponse "Invalid input data or attribute not set"
+// @Failure      401        {object}  models.ErrorResponse "Missing or invalid access token"
+// @Router       /me/attributeVerificationCode [post]
+func (h *AuthHandler) ResendAttributeVerificationCode(context *gin.Context) {
+	accessToken, _, ok := accessTokenFrom(context)
+	if !ok {
+		return
+	}
+
+	var input models.AttributeVerificationCodeInput
+
+	err := context.ShouldBindJSON(&input)
+
+	if err != nil {
+		respondWithInvalidInput(context)
+		return
+	}
+
+	codeDeliveryDetails, err := h.Service.ResendAttributeVerificationCode(context, accessToken, input)
+
+	if err != nil {
+		respondWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, gin.H{"message": "Verification code sent.", "codeDeliveryDetails": codeDeliveryDetails})
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/gin-gonic/gin"
+)
+
+func TestProfile(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	server.signUpConfirmed(t)
+	accessToken := server.signIn(t).AccessToken
+
+	recorder := server.send(t, http.MethodGet, "/me", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("get status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var profile models.UserProfile
+	if err := json.Unmarshal(recorder.Body.Bytes(), &profile); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if profile.Username != testUsername || profile.Attributes["email"] != testUsername {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+
+	recorder = server.send(t, http.MethodPatch, "/me", accessToken, gin.H{})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	recorder = server.send(t, http.MethodPatch, "/me", accessToken, gin.H{"attributes": gin.H{"sub": "other"}})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidParameter)
+
+	recorder = server.send(t, http.MethodPatch, "/me", accessToken, gin.H{"attributes": gin.H{"email": "jane.doe@example.com"}})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("patch status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodPost, "/me/attributeVerificationCode", accessToken, gin.H{"attribute": "email"})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("attributeVerificationCode status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var sent struct {
+		Message             string                        `json:"message"`
+		CodeDeliveryDetails types.CodeDeliveryDetailsType `json:"codeDeliveryDetails"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &sent); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if sent.Message != "Verification code sent." || aws.ToString(sent.CodeDeliveryDetails.AttributeName) != "email" || sent.CodeDeliveryDetails.DeliveryMedium != types.DeliveryMediumTypeEmail {
+		t.Fatalf("unexpected attributeVerificationCode body: %s", recorder.Body)
+	}
+
+	recorder = server.send(t, http.MethodPost, "/me/verifyAttribute", accessToken, gin.H{"attribute": "nickname", "code": "123456"})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	code, _ := server.fake.Code(testUsername, cognitofake.CodeVerifyEmail)
+	recorder = server.send(t, http.MethodPost, "/me/verifyAttribute", accessToken, gin.H{"attribute": "email", "code": code})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("verifyAttribute status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	if user, _ := server.fake.User(testUsername); user.Attributes["email_verified"] != "true" {
+		t.Fatalf("email_verified = %q, want true", user.Attributes["email_verified"])
+	}
+
+	recorder = server.send(t, http.MethodDelete, "/me", accessToken, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	if _, ok := server.fake.User(testUsername); ok {
+		t.Fatal("user still exists after DELETE /me")
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	server := newAuthenticatedTestServer(t)
+	server.signUpConfirmed(t)
+	accessToken := server.signIn(t).AccessToken
+
+	recorder := server.send(t, http.MethodPost, "/me/password", accessToken, gin.H{"previousPassword": testPassword})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidInput)
+
+	recorder = server.send(t, http.MethodPost, "/me/password", accessToken, gin.H{"previousPassword": "Wrong-123", "proposedPassword": "Password-456"})
+	assertError(t, recorder, http.StatusUnauthorized, CodeNotAuthorized)
+
+	recorder = server.send(t, http.MethodPost, "/me/password", accessToken, gin.H{"previousPassword": testPassword, "proposedPassword": "short"})
+	assertError(t, recorder, http.StatusBadRequest, CodeInvalidPassword)
+
+	recorder = server.send(t, http.MethodPost, "/me/password", accessToken, gin.H{"previousPassword": testPassword, "proposedPassword": "Password-456", "signOutOtherSessions": true})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+
+	var response struct {
+		AuthResult models.AuthResponse `json:"authResult"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if response.AuthResult.AccessToken == "" {
+		t.Fatalf("unexpected body: %s", recorder.Body)
+	}
+
+	// The password still changes when signing in again is throttled
+	server.fake.FailNext("InitiateAuth", &types.TooManyRequestsException{Message: aws.String("Too many requests.")})
+	recorder = server.send(t, http.MethodPost, "/me/password", response.AuthResult.AccessToken, gin.H{"previousPassword": "Password-456", "proposedPassword": "Password-789", "signOutOtherSessions": true})
+	if recorder.Code != http.StatusOK || recorder.Body.String() != `{"message":"Password changed, please sign in again."}` {
+		t.Fatalf("got %d %s, want password changed without tokens", recorder.Code, recorder.Body)
+	}
+}
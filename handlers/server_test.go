@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/go-cognito/emulator"
+	"example.com/go-cognito/middleware"
+	"example.com/go-cognito/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Serves the self-service and admin routes behind Authenticate, with tokens signed by the emulator
+func newAuthenticatedTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	issuer := services.CognitoIssuer("us-east-1", "us-east-1_pool")
+	localEmulator, err := emulator.New("client-id", "client-secret", issuer)
+	if err != nil {
+		t.Fatalf("emulator.New() error = %v", err)
+	}
+
+	jwksRouter := gin.New()
+	jwksRouter.GET("/jwks.json", localEmulator.JWKS)
+	jwksServer := httptest.NewServer(jwksRouter)
+	t.Cleanup(jwksServer.Close)
+
+	service := services.NewAuthService(localEmulator, "client-id", "client-secret", "us-east-1", "us-east-1_pool")
+	service.JWKSURL = jwksServer.URL + "/jwks.json"
+	t.Cleanup(service.Close)
+
+	handler := NewAuthHandler(service)
+	adminHandler := NewAdminHandler(services.NewAdminService(localEmulator, service.UserPoolID))
+	middlewareHandler := middleware.NewMiddlewareHandler(service)
+
+	router := gin.New()
+	router.POST("/auth/signUp", handler.SignUp)
+	router.POST("/auth/signIn", handler.SignIn)
+	router.POST("/auth/respondToChallenge", handler.RespondToChallenge)
+	router.POST("/auth/confirmAccount", handler.ConfirmAccount)
+
+	authenticated := router.Group("/", middlewareHandler.Authenticate)
+	authenticated.POST("/mfa/associateSoftwareToken", handler.AssociateSoftwareToken)
+	authenticated.POST("/mfa/verifySoftwareToken", handler.VerifySoftwareToken)
+	authenticated.PUT("/mfa/preference", handler.SetMFAPreference)
+	authenticated.GET("/me", handler.GetProfile)
+	authenticated.PATCH("/me", handler.UpdateProfile)
+	authenticated.DELETE("/me", handler.DeleteProfile)
+	authenticated.POST("/me/password", handler.ChangePassword)
+	authenticated.POST("/me/verifyAttribute", handler.VerifyAttribute)
+	authenticated.POST("/me/attributeVerificationCode", handler.ResendAttributeVerificationCode)
+
+	admin := authenticated.Group("/admin", middlewareHandler.RequireAnyGroup("admin"))
+	admin.GET("/users", adminHandler.ListUsers)
+	admin.POST("/users", adminHandler.CreateUser)
+	admin.GET("/users/:username", adminHandler.GetUser)
+	admin.DELETE("/users/:username", adminHandler.DeleteUser)
+	admin.POST("/users/:username/disable", adminHandler.DisableUser)
+	admin.POST("/users/:username/enable", adminHandler.EnableUser)
+	admin.POST("/users/:username/resetPassword", adminHandler.ResetUserPassword)
+	admin.PUT("/users/:username/password", adminHandler.SetUserPassword)
+	admin.POST("/users/:username/signOut", adminHandler.SignOutUser)
+	admin.GET("/users/:username/groups", adminHandler.ListGroupsForUser)
+	admin.PUT("/users/:username/groups/:groupName", adminHandler.AddUserToGroup)
+	admin.DELETE("/users/:username/groups/:groupName", adminHandler.RemoveUserFromGroup)
+	admin.GET("/groups", adminHandler.ListGroups)
+	admin.POST("/groups", adminHandler.CreateGroup)
+	admin.GET("/groups/:groupName", adminHandler.GetGroup)
+	admin.PATCH("/groups/:groupName", adminHandler.UpdateGroup)
+	admin.DELETE("/groups/:groupName", adminHandler.DeleteGroup)
+	admin.GET("/groups/:groupName/users", adminHandler.ListUsersInGroup)
+
+	return &testServer{router: router, fake: localEmulator.Client}
+}
+
+func (s *testServer) send(t *testing.T, method, path, accessToken string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("encode body: %v", err)
+	}
+
+	request := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, request)
+	return recorder
+}
@@ -0,0 +1,164 @@
+// Writes the service's .env from the outputs of a deployed stack, fetching the client secret from Secrets Manager
+//
+//	cdk deploy -c stage=dev --outputs-file outputs.json
+//	go run ./cmd/envfile -outputs outputs.json -stack InfraStack-dev
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+type secretGetter interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// A .env entry
+type setting struct {
+	key   string
+	value string
+}
+
+// Stack outputs and the config.LoadConfig keys they are written to, in order
+var outputKeys = []struct{ output, key string }{
+	{output: "UserPoolId", key: "USER_POOL_ID"},
+	{output: "ClientId", key: "CLIENT_ID"},
+	{output: "Region", key: "REGION"},
+	{output: "JwksUrl", key: "JWKS_URL"},
+}
+
+func main() {
+	outputsPath := flag.String("outputs", "outputs.json", "file written by cdk deploy --outputs-file")
+	stackName := flag.String("stack", "", "stack to read, may be left out when the file holds one stack")
+	envPath := flag.String("env", "../.env", ".env file to create or update")
+	flag.Parse()
+
+	outputs, err := readOutputs(*outputsPath, *stackName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sdkConfig, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(outputs["Region"]))
+	if err != nil {
+		log.Fatalf("Couldn't load default configuration: %v", err)
+	}
+
+	settings, err := envSettings(context.Background(), secretsmanager.NewFromConfig(sdkConfig), outputs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := writeEnvFile(*envPath, settings); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Wrote %d settings to %s", len(settings), *envPath)
+}
+
+// Reads a stack's outputs from a cdk deploy outputs file, which maps stack names to their outputs
+func readOutputs(path, stackName string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read outputs file: %w", err)
+	}
+
+	var stacks map[string]map[string]string
+	if err := json.Unmarshal(data, &stacks); err != nil {
+		return nil, fmt.Errorf("Couldn't parse outputs file %s: %w", path, err)
+	}
+
+	if stackName == "" {
+		if len(stacks) != 1 {
+			names := make([]string, 0, len(stacks))
+			for name := range stacks {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("Outputs file holds stacks %s, pick one with -stack.", strings.Join(names, ", "))
+		}
+		for name := range stacks {
+			stackName = name
+		}
+	}
+
+	outputs, ok := stacks[stackName]
+	if !ok {
+		return nil, fmt.Errorf("Stack %s not found in %s.", stackName, path)
+	}
+	return outputs, nil
+}
+
+// Maps the stack outputs to .env settings, reading the client secret the stack stored in Secrets Manager
+func envSettings(ctx context.Context, secrets secretGetter, outputs map[string]string) ([]setting, error) {
+	var settings []setting
+	for _, mapping := range outputKeys {
+		value := outputs[mapping.output]
+		if value == "" {
+			return nil, fmt.Errorf("Output %s is missing, deploy the stack again.", mapping.output)
+		}
+		settings = append(settings, setting{key: mapping.key, value: value})
+	}
+
+	secretArn := outputs["ClientSecretArn"]
+	if secretArn == "" {
+		return nil, errors.New("Output ClientSecretArn is missing, deploy the stack again.")
+	}
+
+	secret, err := secrets.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretArn)})
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read client secret: %w", err)
+	}
+	settings = append(settings, setting{key: "CLIENT_SECRET", value: aws.ToString(secret.SecretString)})
+
+	// Only stacks with a hosted UI domain have one
+	if domain := outputs["HostedUIDomain"]; domain != "" {
+		settings = append(settings, setting{key: "HOSTED_UI_DOMAIN", value: domain})
+	}
+
+	return settings, nil
+}
+
+// Updates the settings in place and appends new ones, other lines of an existing file are kept
+func writeEnvFile(path string, settings []setting) error {
+	var lines []string
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("Couldn't read %s: %w", path, err)
+	}
+
+	for _, s := range settings {
+		line := s.key + "=" + s.value
+		replaced := false
+		for i, existing := range lines {
+			key, _, found := strings.Cut(existing, "=")
+			if found && strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(key), "export ")) == s.key {
+				lines[i] = line
+				replaced = true
+			}
+		}
+		if !replaced {
+			lines = append(lines, line)
+		}
+	}
+
+	// The file holds the client secret
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		return fmt.Errorf("Couldn't write %s: %w", path, err)
+	}
+	return nil
+}
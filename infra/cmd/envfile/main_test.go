@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+type fakeSecrets struct {
+	secrets map[string]string
+}
+
+func (f *fakeSecrets) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	secret, ok := f.secrets[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, errors.New("secret not found")
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(secret)}, nil
+}
+
+func stackOutputs() map[string]string {
+	return map[string]string{
+		"UserPoolId":      "us-east-1_abc123",
+		"ClientId":        "client-id",
+		"Region":          "us-east-1",
+		"Issuer":          "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_abc123",
+		"JwksUrl":         "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_abc123/.well-known/jwks.json",
+		"ClientSecretArn": "arn:aws:secretsmanager:us-east-1:123456789012:secret:client-secret",
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadOutputs(t *testing.T) {
+	path := writeFile(t, "outputs.json", `{"InfraStack-dev": {"ClientId": "dev-client"}, "InfraStack-prod": {"ClientId": "prod-client"}}`)
+
+	outputs, err := readOutputs(path, "InfraStack-prod")
+	if err != nil {
+		t.Fatalf("readOutputs() error = %v", err)
+	}
+	if outputs["ClientId"] != "prod-client" {
+		t.Fatalf("unexpected outputs: %v", outputs)
+	}
+
+	if _, err := readOutputs(path, ""); err == nil || !strings.Contains(err.Error(), "InfraStack-dev, InfraStack-prod") {
+		t.Fatalf("readOutputs() without stack error = %v", err)
+	}
+	if _, err := readOutputs(path, "InfraStack-staging"); err == nil {
+		t.Fatal("readOutputs() with unknown stack succeeded")
+	}
+
+	// A file with one stack needs no stack name
+	single := writeFile(t, "outputs.json", `{"InfraStack-dev": {"ClientId": "dev-client"}}`)
+	if outputs, err := readOutputs(single, ""); err != nil || outputs["ClientId"] != "dev-client" {
+		t.Fatalf("readOutputs() = %v, %v", outputs, err)
+	}
+}
+
+func TestEnvSettings(t *testing.T) {
+	outputs := stackOutputs()
+	secrets := &fakeSecrets{secrets: map[string]string{outputs["ClientSecretArn"]: "client-secret"}}
+
+	settings, err := envSettings(context.Background(), secrets, outputs)
+	if err != nil {
+		t.Fatalf("envSettings() error = %v", err)
+	}
+
+	want := []setting{
+		{key: "USER_POOL_ID", value: "us-east-1_abc123"},
+		{key: "CLIENT_ID", value: "client-id"},
+		{key: "REGION", value: "us-east-1"},
+		{key: "JWKS_URL", value: outputs["JwksUrl"]},
+		{key: "CLIENT_SECRET", value: "client-secret"},
+	}
+	if len(settings) != len(want) {
+		t.Fatalf("envSettings() = %v, want %v", settings, want)
+	}
+	for i := range want {
+		if settings[i] != want[i] {
+			t.Fatalf("envSettings() = %v, want %v", settings, want)
+		}
+	}
+
+	outputs["HostedUIDomain"] = "https://myapp.auth.us-east-1.amazoncognito.com"
+	settings, _ = envSettings(context.Background(), secrets, outputs)
+	if last := settings[len(settings)-1]; last.key != "HOSTED_UI_DOMAIN" || last.value != outputs["HostedUIDomain"] {
+		t.Fatalf("unexpected settings: %v", settings)
+	}
+
+	delete(outputs, "ClientId")
+	if _, err := envSettings(context.Background(), secrets, outputs); err == nil || !strings.Contains(err.Error(), "ClientId") {
+		t.Fatalf("envSettings() without ClientId error = %v", err)
+	}
+}
+
+func TestWriteEnvFile(t *testing.T) {
+	path := writeFile(t, ".env", "# Local settings\nCLIENT_ID=old-client\nLOCAL_MODE=false\n")
+
+	settings := []setting{{key: "CLIENT_ID", value: "client-id"}, {key: "REGION", value: "us-east-1"}}
+	if err := writeEnvFile(path, settings); err != nil {
+		t.Fatalf("writeEnvFile() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := "# Local settings\nCLIENT_ID=client-id\nLOCAL_MODE=false\nREGION=us-east-1\n"
+	if string(data) != want {
+		t.Fatalf("file = %q, want %q", data, want)
+	}
+
+	created := filepath.Join(t.TempDir(), ".env")
+	if err := writeEnvFile(created, settings); err != nil {
+		t.Fatalf("writeEnvFile() new file error = %v", err)
+	}
+	data, _ = os.ReadFile(created)
+	if string(data) != "CLIENT_ID=client-id\nREGION=us-east-1\n" {
+		t.Fatalf("new file = %q", data)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awscognito"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+)
+
+// Identity providers offered by the hosted UI, each one is created only when configured
+type IdentityProvidersProps struct {
+	Google   *SocialProviderProps
+	Facebook *SocialProviderProps
+	Apple    *AppleProviderProps
+	Oidc     []OidcProviderProps
+	Saml     []SamlProviderProps
+}
+
+// OAuth app registered with Google, or Facebook where the client ID is the app ID
+type SocialProviderProps struct {
+	ClientId     string
+	ClientSecret awscdk.SecretValue
+}
+
+type AppleProviderProps struct {
+	// Services ID used as the client ID
+	ServicesId string
+	TeamId     string
+	KeyId      string
+	PrivateKey awscdk.SecretValue
+}
+
+type OidcProviderProps struct {
+	// Shown on the hosted UI and passed as provider to /auth/authorize
+	Name         string
+	ClientId     string
+	ClientSecret awscdk.SecretValue
+	IssuerUrl    string
+}
+
+type SamlProviderProps struct {
+	// Shown on the hosted UI and passed as provider to /auth/authorize
+	Name        string
+	MetadataUrl string
+	// SAML attributes holding the email and name, default to the standard claim URIs
+	EmailAttribute string
+	NameAttribute  string
+}
+
+// Claim URIs most SAML identity providers, e.g. Entra ID and AD FS, send
+const (
+	samlEmailClaim = "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress"
+	samlNameClaim  = "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name"
+)
+
+// Creates the configured identity providers. Every provider maps its user's email and name,
+// which the service reads from the ID token.
+func newIdentityProviders(scope constructs.Construct, pool awscognito.IUserPool, props *IdentityProvidersProps) []awscognito.IUserPoolIdentityProvider {
+	var providers []awscognito.IUserPoolIdentityProvider
+	if props == nil {
+		return providers
+	}
+
+	if google := props.Google; google != nil {
+		providers = append(providers, awscognito.NewUserPoolIdentityProviderGoogle(scope, jsii.String("GoogleProvider"), &awscognito.UserPoolIdentityProviderGoogleProps{
+			UserPool:          pool,
+			ClientId:          jsii.String(google.ClientId),
+			ClientSecretValue: google.ClientSecret,
+			Scopes:            jsii.Strings("openid", "email", "profile"),
+			AttributeMapping: &awscognito.AttributeMapping{
+				Email:    awscognito.ProviderAttribute_GOOGLE_EMAIL(),
+				Fullname: awscognito.ProviderAttribute_GOOGLE_NAME(),
+			},
+		}))
+	}
+
+	if facebook := props.Facebook; facebook != nil {
+		providers = append(providers, awscognito.NewUserPoolIdentityProviderFacebook(scope, jsii.String("FacebookProvider"), &awscognito.UserPoolIdentityProviderFacebookProps{
+			UserPool: pool,
+			ClientId: jsii.String(facebook.ClientId),
+			// Resolved by CloudFormation, the secret stays out of the template
+			ClientSecret: facebook.ClientSecret.UnsafeUnwrap(),
+			Scopes:       jsii.Strings("public_profile", "email"),
+			AttributeMapping: &awscognito.AttributeMapping{
+				Email:    awscognito.ProviderAttribute_FACEBOOK_EMAIL(),
+				Fullname: awscognito.ProviderAttribute_FACEBOOK_NAME(),
+			},
+		}))
+	}
+
+	if apple := props.Apple; apple != nil {
+		providers = append(providers, awscognito.NewUserPoolIdentityProviderApple(scope, jsii.String("AppleProvider"), &awscognito.UserPoolIdentityProviderAppleProps{
+			UserPool:        pool,
+			ClientId:        jsii.String(apple.ServicesId),
+			TeamId:          jsii.String(apple.TeamId),
+			KeyId:           jsii.String(apple.KeyId),
+			PrivateKeyValue: apple.PrivateKey,
+			Scopes:          jsii.Strings("email", "name"),
+			AttributeMapping: &awscognito.AttributeMapping{
+				Email:    awscognito.ProviderAttribute_APPLE_EMAIL(),
+				Fullname: awscognito.ProviderAttribute_APPLE_NAME(),
+			},
+		}))
+	}
+
+	for _, oidc := range props.Oidc {
+		providers = append(providers, awscognito.NewUserPoolIdentityProviderOidc(scope, jsii.String("OidcProvider"+oidc.Name), &awscognito.UserPoolIdentityProviderOidcProps{
+			UserPool:     pool,
+			Name:         jsii.String(oidc.Name),
+			ClientId:     jsii.String(oidc.ClientId),
+			ClientSecret: oidc.ClientSecret.UnsafeUnwrap(),
+			IssuerUrl:    jsii.String(oidc.IssuerUrl),
+			Scopes:       jsii.Strings("openid", "email", "profile"),
+			AttributeMapping: &awscognito.AttributeMapping{
+				Email:    awscognito.ProviderAttribute_Other(jsii.String("email")),
+				Fullname: awscognito.ProviderAttribute_Other(jsii.String("name")),
+			},
+		}))
+	}
+
+	for _, saml := range props.Saml {
+		providers = append(providers, awscognito.NewUserPoolIdentityProviderSaml(scope, jsii.String("SamlProvider"+saml.Name), &awscognito.UserPoolIdentityProviderSamlProps{
+			UserPool: pool,
+			Name:     jsii.String(saml.Name),
+			Metadata: awscognito.UserPoolIdentityProviderSamlMetadata_Url(jsii.String(saml.MetadataUrl)),
+			AttributeMapping: &awscognito.AttributeMapping{
+				Email:    awscognito.ProviderAttribute_Other(jsii.String(valueOrDefault(saml.EmailAttribute, samlEmailClaim))),
+				Fullname: awscognito.ProviderAttribute_Other(jsii.String(valueOrDefault(saml.NameAttribute, samlNameClaim))),
+			},
+		}))
+	}
+
+	return providers
+}
+
+// Reads the identity providers from the environment. Secrets are referenced by their Secrets Manager name.
+func identityProvidersFromEnv() *IdentityProvidersProps {
+	props := &IdentityProvidersProps{}
+
+	if clientId := os.Getenv("GOOGLE_CLIENT_ID"); clientId != "" {
+		props.Google = &SocialProviderProps{
+			ClientId:     clientId,
+			ClientSecret: secretFromEnv("GOOGLE_CLIENT_SECRET_NAME"),
+		}
+	}
+
+	if appId := os.Getenv("FACEBOOK_APP_ID"); appId != "" {
+		props.Facebook = &SocialProviderProps{
+			ClientId:     appId,
+			ClientSecret: secretFromEnv("FACEBOOK_APP_SECRET_NAME"),
+		}
+	}
+
+	if servicesId := os.Getenv("APPLE_SERVICES_ID"); servicesId != "" {
+		props.Apple = &AppleProviderProps{
+			ServicesId: servicesId,
+			TeamId:     requiredEnv("APPLE_TEAM_ID"),
+			KeyId:      requiredEnv("APPLE_KEY_ID"),
+			PrivateKey: secretFromEnv("APPLE_PRIVATE_KEY_SECRET_NAME"),
+		}
+	}
+
+	if name := os.Getenv("OIDC_PROVIDER_NAME"); name != "" {
+		props.Oidc = append(props.Oidc, OidcProviderProps{
+			Name:         name,
+			ClientId:     requiredEnv("OIDC_CLIENT_ID"),
+			ClientSecret: secretFromEnv("OIDC_CLIENT_SECRET_NAME"),
+			IssuerUrl:    requiredEnv("OIDC_ISSUER_URL"),
+		})
+	}
+
+	if name := os.Getenv("SAML_PROVIDER_NAME"); name != "" {
+		props.Saml = append(props.Saml, SamlProviderProps{
+			Name:           name,
+			MetadataUrl:    requiredEnv("SAML_METADATA_URL"),
+			EmailAttribute: os.Getenv("SAML_EMAIL_ATTRIBUTE"),
+			NameAttribute:  os.Getenv("SAML_NAME_ATTRIBUTE"),
+		})
+	}
+
+	return props
+}
+
+// References the Secrets Manager secret named by an environment variable
+func secretFromEnv(key string) awscdk.SecretValue {
+	return awscdk.SecretValue_SecretsManager(jsii.String(requiredEnv(key)), nil)
+}
+
+// Reads a setting a configured provider cannot do without, failing before synth with its name
+func requiredEnv(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Fatalf("%s must be set when its identity provider is configured", key)
+	}
+	return value
+}
+
+func valueOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
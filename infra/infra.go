@@ -3,9 +3,11 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awscognito"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssecretsmanager"
 	"github.com/joho/godotenv"
 
 	// "github.com/aws/aws-cdk-go/awscdk/v2/awssqs"
@@ -15,14 +17,46 @@ import (
 
 type InfraStackProps struct {
 	awscdk.StackProps
+	// Verified SES identity that passwordless sign-in codes are sent from
+	SenderEmail string
+	// Hosted UI domain prefix, unique within the region, e.g. myapp for myapp.auth.<region>.amazoncognito.com
+	DomainPrefix string
+	// URLs the hosted UI may redirect to after sign in and sign out
+	CallbackUrls []string
+	LogoutUrls   []string
+	// Social and enterprise providers users can sign in with at the hosted UI
+	IdentityProviders *IdentityProvidersProps
+
+	// Default to userpool-1 and user-pool-client-1
+	UserPoolName string
+	ClientName   string
+	// Cognito's default policy when nil, 8 characters with every character class
+	PasswordPolicy *awscognito.PasswordPolicy
+	// Off unless set. REQUIRED also needs the MFA_SETUP challenge, which the service does not answer yet.
+	Mfa awscognito.Mfa
+	// Factors offered when MFA is on, defaults to TOTP
+	MfaSecondFactor *awscognito.MfaSecondFactor
+	// Defaults to a verified phone number without MFA, then email
+	AccountRecovery awscognito.AccountRecovery
+	// Blocks deleting the user pool until turned off
+	DeletionProtection bool
+	// What happens to the user pool when it leaves the stack, defaults to RETAIN
+	RemovalPolicy awscdk.RemovalPolicy
 }
 
+// Where the API and a local frontend run during development
+var (
+	defaultCallbackUrls = []string{"http://localhost:8080/auth/callback"}
+	defaultLogoutUrls   = []string{"http://localhost:3000/"}
+)
+
 func NewInfraStack(scope constructs.Construct, id string, props *InfraStackProps) awscdk.Stack {
-	var sprops awscdk.StackProps
+	var p InfraStackProps
 	if props != nil {
-		sprops = props.StackProps
+		p = *props
 	}
-	stack := awscdk.NewStack(scope, &id, &sprops)
+	p.applyDefaults()
+	stack := awscdk.NewStack(scope, &id, &p.StackProps)
 
 	// The code that defines your stack goes here
 
@@ -31,47 +65,175 @@ func NewInfraStack(scope constructs.Construct, id string, props *InfraStackProps
 	// 	VisibilityTimeout: awscdk.Duration_Seconds(jsii.Number(300)),
 	// })
 
+	// Passwordless sign in with an emailed code, started with the CUSTOM_AUTH flow
+	triggers := newAuthChallengeTriggers(stack, p.SenderEmail)
+
 	pool := awscognito.NewUserPool(stack, jsii.String("UserPool"), &awscognito.UserPoolProps{
 		AutoVerify: &awscognito.AutoVerifiedAttrs{
 			Email: jsii.Bool(true),
 		},
 		SelfSignUpEnabled: jsii.Bool(true),
-		UserPoolName:      jsii.String("userpool-1"),
+		UserPoolName:      jsii.String(p.UserPoolName),
 		UserVerification: &awscognito.UserVerificationConfig{
 			EmailStyle:   awscognito.VerificationEmailStyle_CODE,
 			EmailSubject: jsii.String("Confirm Sign-Up"),
 		},
+		LambdaTriggers: &awscognito.UserPoolTriggers{
+			DefineAuthChallenge:         triggers.Define,
+			CreateAuthChallenge:         triggers.Create,
+			VerifyAuthChallengeResponse: triggers.Verify,
+		},
+		PasswordPolicy:     p.PasswordPolicy,
+		Mfa:                p.Mfa,
+		MfaSecondFactor:    p.MfaSecondFactor,
+		AccountRecovery:    p.AccountRecovery,
+		DeletionProtection: jsii.Bool(p.DeletionProtection),
+		RemovalPolicy:      p.RemovalPolicy,
 	})
 
+	// Users from each provider are linked to the pool by their email and name
+	providers := newIdentityProviders(stack, pool, p.IdentityProviders)
+	supportedProviders := []awscognito.UserPoolClientIdentityProvider{
+		awscognito.UserPoolClientIdentityProvider_COGNITO(),
+	}
+	for _, provider := range providers {
+		supportedProviders = append(supportedProviders, awscognito.UserPoolClientIdentityProvider_Custom(provider.ProviderName()))
+	}
+
 	userPoolClientOptions := &awscognito.UserPoolClientOptions{
 		AuthFlows: &awscognito.AuthFlow{
 			User:         jsii.Bool(true),
 			UserPassword: jsii.Bool(true),
 			UserSrp:      jsii.Bool(true),
+			Custom:       jsii.Bool(true),
 		},
 		GenerateSecret:     jsii.Bool(true),
-		UserPoolClientName: jsii.String("user-pool-client-1"),
+		UserPoolClientName: jsii.String(p.ClientName),
+		// Hosted UI sign in, the API exchanges the code at /auth/callback with PKCE and the client secret
+		OAuth: &awscognito.OAuthSettings{
+			Flows: &awscognito.OAuthFlows{
+				AuthorizationCodeGrant: jsii.Bool(true),
+			},
+			Scopes: &[]awscognito.OAuthScope{
+				awscognito.OAuthScope_OPENID(),
+				awscognito.OAuthScope_EMAIL(),
+				awscognito.OAuthScope_PROFILE(),
+			},
+			CallbackUrls: jsii.Strings(p.CallbackUrls...),
+			LogoutUrls:   jsii.Strings(p.LogoutUrls...),
+		},
+		SupportedIdentityProviders: &supportedProviders,
+	}
+
+	client := pool.AddClient(jsii.String("customer-app-client"), userPoolClientOptions)
+
+	// The client can only name providers that already exist
+	for _, provider := range providers {
+		client.Node().AddDependency(provider)
 	}
 
-	pool.AddClient(jsii.String("customer-app-client"), userPoolClientOptions)
+	// The hosted UI is served from a Cognito domain, skipped until a prefix is chosen
+	var domain awscognito.UserPoolDomain
+	if p.DomainPrefix != "" {
+		domain = pool.AddDomain(jsii.String("UserPoolDomain"), &awscognito.UserPoolDomainOptions{
+			CognitoDomain: &awscognito.CognitoDomainOptions{
+				DomainPrefix: jsii.String(p.DomainPrefix),
+			},
+		})
+	}
+
+	// The client secret is kept out of the outputs, cmd/envfile reads it from Secrets Manager
+	clientSecret := awssecretsmanager.NewSecret(stack, jsii.String("ClientSecret"), &awssecretsmanager.SecretProps{
+		Description:       jsii.String("Secret of the " + p.ClientName + " app client"),
+		SecretStringValue: client.UserPoolClientSecret(),
+		RemovalPolicy:     p.RemovalPolicy,
+	})
+
+	// Settings the service reads from .env, write them with cmd/envfile after cdk deploy --outputs-file
+	outputs := map[string]*string{
+		"UserPoolId":      pool.UserPoolId(),
+		"ClientId":        client.UserPoolClientId(),
+		"Region":          stack.Region(),
+		"Issuer":          pool.UserPoolProviderUrl(),
+		"JwksUrl":         jsii.String(*pool.UserPoolProviderUrl() + "/.well-known/jwks.json"),
+		"ClientSecretArn": clientSecret.SecretArn(),
+	}
+	if domain != nil {
+		outputs["HostedUIDomain"] = domain.BaseUrl(nil)
+	}
+	for id, value := range outputs {
+		awscdk.NewCfnOutput(stack, jsii.String(id), &awscdk.CfnOutputProps{Value: value})
+	}
 
 	return stack
 }
 
+// Fills in the settings left unset, keeping the pool as it was before they were configurable
+func (p *InfraStackProps) applyDefaults() {
+	if p.UserPoolName == "" {
+		p.UserPoolName = "userpool-1"
+	}
+	if p.ClientName == "" {
+		p.ClientName = "user-pool-client-1"
+	}
+	if len(p.CallbackUrls) == 0 {
+		p.CallbackUrls = defaultCallbackUrls
+	}
+	if len(p.LogoutUrls) == 0 {
+		p.LogoutUrls = defaultLogoutUrls
+	}
+	if p.Mfa == "" {
+		p.Mfa = awscognito.Mfa_OFF
+	}
+	if p.Mfa != awscognito.Mfa_OFF && p.MfaSecondFactor == nil {
+		p.MfaSecondFactor = &awscognito.MfaSecondFactor{Otp: jsii.Bool(true), Sms: jsii.Bool(false)}
+	}
+	if p.AccountRecovery == "" {
+		p.AccountRecovery = awscognito.AccountRecovery_PHONE_WITHOUT_MFA_AND_EMAIL
+	}
+	if p.RemovalPolicy == "" {
+		p.RemovalPolicy = awscdk.RemovalPolicy_RETAIN
+	}
+}
+
 func main() {
 	defer jsii.Close()
 
 	app := awscdk.NewApp(nil)
 
-	NewInfraStack(app, "InfraStack", &InfraStackProps{
-		awscdk.StackProps{
-			Env: env(),
-		},
-	})
+	// Pick the stage with `cdk deploy -c stage=prod`
+	stage, _ := app.Node().TryGetContext(jsii.String("stage")).(string)
+
+	id, props, ok := stageStack(stage)
+	if !ok {
+		log.Fatalf("Unknown stage %q, expected dev, staging or prod", stage)
+	}
+
+	props.StackProps = awscdk.StackProps{
+		Env: env(),
+	}
+	props.SenderEmail = os.Getenv("SENDER_EMAIL")
+	props.DomainPrefix = os.Getenv("DOMAIN_PREFIX")
+	props.CallbackUrls = splitList(os.Getenv("CALLBACK_URLS"))
+	props.LogoutUrls = splitList(os.Getenv("LOGOUT_URLS"))
+	props.IdentityProviders = identityProvidersFromEnv()
+
+	NewInfraStack(app, id, props)
 
 	app.Synth(nil)
 }
 
+// Splits a comma-separated environment variable, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // env determines the AWS environment (account+region) in which our stack is to
 // be deployed. For more information see: https://docs.aws.amazon.com/cdk/latest/guide/environments.html
 func env() *awscdk.Environment {
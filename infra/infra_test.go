@@ -1,26 +1,410 @@
 package main
 
-// import (
-// 	"testing"
-
-// 	"github.com/aws/aws-cdk-go/awscdk/v2"
-// 	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
-// 	"github.com/aws/jsii-runtime-go"
-// )
-
-// example tests. To run these tests, uncomment this file along with the
-// example resource in infra_test.go
-// func TestInfraStack(t *testing.T) {
-// 	// GIVEN
-// 	app := awscdk.NewApp(nil)
-
-// 	// WHEN
-// 	stack := NewInfraStack(app, "MyStack", nil)
-
-// 	// THEN
-// 	template := assertions.Template_FromStack(stack, nil)
-
-// 	template.HasResourceProperties(jsii.String("AWS::SQS::Queue"), map[string]interface{}{
-// 		"VisibilityTimeout": 300,
-// 	})
-// }
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/assertions"
+	"github.com/aws/jsii-runtime-go"
+)
+
+// Synthesizes the stack without bundling the Lambda triggers, which would need Go or Docker
+func newTestTemplate(t *testing.T, props *InfraStackProps) assertions.Template {
+	t.Helper()
+
+	app := awscdk.NewApp(&awscdk.AppProps{
+		Context: &map[string]interface{}{
+			"aws:cdk:bundling-stacks": []string{},
+		},
+	})
+	stack := NewInfraStack(app, "TestStack", props)
+
+	return assertions.Template_FromStack(stack, nil)
+}
+
+func testIdentityProviders() *IdentityProvidersProps {
+	return &IdentityProvidersProps{
+		Google: &SocialProviderProps{
+			ClientId:     "google-client",
+			ClientSecret: awscdk.SecretValue_SecretsManager(jsii.String("google-secret"), nil),
+		},
+		Facebook: &SocialProviderProps{
+			ClientId:     "facebook-app",
+			ClientSecret: awscdk.SecretValue_SecretsManager(jsii.String("facebook-secret"), nil),
+		},
+		Apple: &AppleProviderProps{
+			ServicesId: "com.example.app",
+			TeamId:     "TEAM123456",
+			KeyId:      "KEY1234567",
+			PrivateKey: awscdk.SecretValue_SecretsManager(jsii.String("apple-key"), nil),
+		},
+		Oidc: []OidcProviderProps{{
+			Name:         "Okta",
+			ClientId:     "okta-client",
+			ClientSecret: awscdk.SecretValue_SecretsManager(jsii.String("okta-secret"), nil),
+			IssuerUrl:    "https://example.okta.com",
+		}},
+		Saml: []SamlProviderProps{{
+			Name:        "EntraID",
+			MetadataUrl: "https://login.microsoftonline.com/tenant/federationmetadata.xml",
+		}},
+	}
+}
+
+func TestNoIdentityProviders(t *testing.T) {
+	template := newTestTemplate(t, nil)
+
+	template.ResourceCountIs(jsii.String("AWS::Cognito::UserPoolIdentityProvider"), jsii.Number(0))
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolClient"), map[string]interface{}{
+		"SupportedIdentityProviders": []string{"COGNITO"},
+	})
+}
+
+func TestIdentityProviders(t *testing.T) {
+	template := newTestTemplate(t, &InfraStackProps{IdentityProviders: testIdentityProviders()})
+
+	template.ResourceCountIs(jsii.String("AWS::Cognito::UserPoolIdentityProvider"), jsii.Number(5))
+
+	tests := []struct {
+		providerType string
+		mapping      map[string]string
+	}{
+		{"Google", map[string]string{"email": "email", "name": "name"}},
+		{"Facebook", map[string]string{"email": "email", "name": "name"}},
+		{"SignInWithApple", map[string]string{"email": "email", "name": "name"}},
+		{"OIDC", map[string]string{"email": "email", "name": "name"}},
+		{"SAML", map[string]string{"email": samlEmailClaim, "name": samlNameClaim}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.providerType, func(t *testing.T) {
+			template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolIdentityProvider"), map[string]interface{}{
+				"ProviderType":     tt.providerType,
+				"AttributeMapping": tt.mapping,
+			})
+		})
+	}
+
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolClient"), map[string]interface{}{
+		"SupportedIdentityProviders": assertions.Match_ArrayWith(&[]interface{}{
+			"COGNITO",
+			map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("^GoogleProvider"))},
+		}),
+	})
+}
+
+func TestIdentityProvidersFromEnv(t *testing.T) {
+	for _, key := range []string{"GOOGLE_CLIENT_ID", "FACEBOOK_APP_ID", "APPLE_SERVICES_ID"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("OIDC_PROVIDER_NAME", "Okta")
+	t.Setenv("OIDC_CLIENT_ID", "okta-client")
+	t.Setenv("OIDC_CLIENT_SECRET_NAME", "okta-secret")
+	t.Setenv("OIDC_ISSUER_URL", "https://example.okta.com")
+	t.Setenv("SAML_PROVIDER_NAME", "EntraID")
+	t.Setenv("SAML_METADATA_URL", "https://login.microsoftonline.com/metadata.xml")
+
+	props := identityProvidersFromEnv()
+	if props.Google != nil || props.Facebook != nil || props.Apple != nil {
+		t.Fatalf("unexpected social providers: %+v", props)
+	}
+	if len(props.Oidc) != 1 || props.Oidc[0].ClientId != "okta-client" || props.Oidc[0].IssuerUrl != "https://example.okta.com" {
+		t.Fatalf("unexpected OIDC providers: %+v", props.Oidc)
+	}
+	if len(props.Saml) != 1 || props.Saml[0].MetadataUrl != "https://login.microsoftonline.com/metadata.xml" {
+		t.Fatalf("unexpected SAML providers: %+v", props.Saml)
+	}
+}
+
+// A provider without its secret name fails with the missing setting instead of a jsii error
+func TestIdentityProvidersFromEnvRequiresSettings(t *testing.T) {
+	if os.Getenv("IDENTITY_PROVIDERS_FROM_ENV") == "1" {
+		identityProvidersFromEnv()
+		return
+	}
+
+	command := exec.Command(os.Args[0], "-test.run=^TestIdentityProvidersFromEnvRequiresSettings$")
+	command.Env = append(os.Environ(), "IDENTITY_PROVIDERS_FROM_ENV=1", "GOOGLE_CLIENT_ID=google-client", "GOOGLE_CLIENT_SECRET_NAME=")
+	output, err := command.CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "GOOGLE_CLIENT_SECRET_NAME must be set") {
+		t.Fatalf("identityProvidersFromEnv() = %v, output %s", err, output)
+	}
+}
+
+func TestIdentityProviderSecretsStayOutOfTemplate(t *testing.T) {
+	template := newTestTemplate(t, &InfraStackProps{IdentityProviders: testIdentityProviders()})
+
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolIdentityProvider"), map[string]interface{}{
+		"ProviderType": "Facebook",
+		"ProviderDetails": assertions.Match_ObjectLike(&map[string]interface{}{
+			"client_secret": "{{resolve:secretsmanager:facebook-secret:SecretString:::}}",
+		}),
+	})
+}
+
+func TestClientDependsOnIdentityProviders(t *testing.T) {
+	template := newTestTemplate(t, &InfraStackProps{IdentityProviders: testIdentityProviders()})
+
+	// Creating the client before its providers fails the deployment
+	template.HasResource(jsii.String("AWS::Cognito::UserPoolClient"), map[string]interface{}{
+		"DependsOn": assertions.Match_ArrayWith(&[]interface{}{
+			assertions.Match_StringLikeRegexp(jsii.String("^SamlProviderEntraID")),
+		}),
+	})
+}
+
+func TestUserPool(t *testing.T) {
+	template := newTestTemplate(t, nil)
+
+	template.ResourceCountIs(jsii.String("AWS::Cognito::UserPool"), jsii.Number(1))
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPool"), map[string]interface{}{
+		"UserPoolName":           "userpool-1",
+		"AutoVerifiedAttributes": []string{"email"},
+		// Self sign up is on, the service's /auth/signUp depends on it
+		"AdminCreateUserConfig": map[string]interface{}{
+			"AllowAdminCreateUserOnly": false,
+		},
+		"VerificationMessageTemplate": assertions.Match_ObjectLike(&map[string]interface{}{
+			"DefaultEmailOption": "CONFIRM_WITH_CODE",
+			"EmailSubject":       "Confirm Sign-Up",
+		}),
+	})
+}
+
+func TestUserPoolTriggers(t *testing.T) {
+	template := newTestTemplate(t, &InfraStackProps{SenderEmail: "no-reply@example.com"})
+
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPool"), map[string]interface{}{
+		"LambdaConfig": map[string]interface{}{
+			"DefineAuthChallenge":         map[string]interface{}{"Fn::GetAtt": []interface{}{assertions.Match_StringLikeRegexp(jsii.String("^DefineAuthChallenge")), "Arn"}},
+			"CreateAuthChallenge":         map[string]interface{}{"Fn::GetAtt": []interface{}{assertions.Match_StringLikeRegexp(jsii.String("^CreateAuthChallenge")), "Arn"}},
+			"VerifyAuthChallengeResponse": map[string]interface{}{"Fn::GetAtt": []interface{}{assertions.Match_StringLikeRegexp(jsii.String("^VerifyAuthChallenge")), "Arn"}},
+		},
+	})
+
+	// The custom resource that reads the client secret brings a function of its own
+	functions := template.FindResources(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+		"Properties": map[string]interface{}{
+			"Runtime":       "provided.al2023",
+			"Architectures": []string{"arm64"},
+			"Handler":       "bootstrap",
+		},
+	})
+	if len(*functions) != 3 {
+		t.Fatalf("found %d trigger functions, want 3", len(*functions))
+	}
+
+	// Cognito may invoke each trigger
+	template.ResourceCountIs(jsii.String("AWS::Lambda::Permission"), jsii.Number(3))
+	template.AllResourcesProperties(jsii.String("AWS::Lambda::Permission"), map[string]interface{}{
+		"Action":    "lambda:InvokeFunction",
+		"Principal": "cognito-idp.amazonaws.com",
+	})
+}
+
+func TestCreateAuthChallengeSendsEmail(t *testing.T) {
+	template := newTestTemplate(t, &InfraStackProps{SenderEmail: "no-reply@example.com"})
+
+	template.HasResourceProperties(jsii.String("AWS::Lambda::Function"), map[string]interface{}{
+		"Environment": map[string]interface{}{
+			"Variables": map[string]interface{}{"SENDER_EMAIL": "no-reply@example.com"},
+		},
+	})
+
+	template.HasResourceProperties(jsii.String("AWS::IAM::Policy"), map[string]interface{}{
+		"PolicyDocument": map[string]interface{}{
+			"Statement": assertions.Match_ArrayWith(&[]interface{}{
+				assertions.Match_ObjectLike(&map[string]interface{}{
+					"Action": "ses:SendEmail",
+					"Effect": "Allow",
+				}),
+			}),
+		},
+	})
+}
+
+func TestUserPoolClient(t *testing.T) {
+	template := newTestTemplate(t, nil)
+
+	template.ResourceCountIs(jsii.String("AWS::Cognito::UserPoolClient"), jsii.Number(1))
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolClient"), map[string]interface{}{
+		"ClientName": "user-pool-client-1",
+		// The service signs every request with a secret hash
+		"GenerateSecret": true,
+		// USER_PASSWORD_AUTH and USER_SRP_AUTH back AUTH_FLOW, CUSTOM_AUTH backs passwordless sign in
+		"ExplicitAuthFlows": assertions.Match_ArrayEquals(&[]interface{}{
+			"ALLOW_USER_PASSWORD_AUTH",
+			"ALLOW_CUSTOM_AUTH",
+			"ALLOW_USER_SRP_AUTH",
+			"ALLOW_USER_AUTH",
+			"ALLOW_REFRESH_TOKEN_AUTH",
+		}),
+	})
+}
+
+func TestUserPoolClientOAuth(t *testing.T) {
+	template := newTestTemplate(t, nil)
+
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolClient"), map[string]interface{}{
+		"AllowedOAuthFlows":               []string{"code"},
+		"AllowedOAuthFlowsUserPoolClient": true,
+		"AllowedOAuthScopes":              []string{"openid", "email", "profile"},
+		"CallbackURLs":                    defaultCallbackUrls,
+		"LogoutURLs":                      defaultLogoutUrls,
+	})
+
+	template = newTestTemplate(t, &InfraStackProps{
+		CallbackUrls: []string{"https://api.example.com/auth/callback"},
+		LogoutUrls:   []string{"https://example.com/"},
+	})
+
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolClient"), map[string]interface{}{
+		"CallbackURLs": []string{"https://api.example.com/auth/callback"},
+		"LogoutURLs":   []string{"https://example.com/"},
+	})
+}
+
+func TestUserPoolDomain(t *testing.T) {
+	template := newTestTemplate(t, nil)
+	template.ResourceCountIs(jsii.String("AWS::Cognito::UserPoolDomain"), jsii.Number(0))
+
+	template = newTestTemplate(t, &InfraStackProps{DomainPrefix: "go-cognito-test"})
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolDomain"), map[string]interface{}{
+		"Domain":     "go-cognito-test",
+		"UserPoolId": map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("^UserPool"))},
+	})
+}
+
+func TestUserPoolDefaults(t *testing.T) {
+	template := newTestTemplate(t, nil)
+
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPool"), map[string]interface{}{
+		"MfaConfiguration":   "OFF",
+		"DeletionProtection": "INACTIVE",
+		"AccountRecoverySetting": map[string]interface{}{
+			"RecoveryMechanisms": []interface{}{
+				map[string]interface{}{"Name": "verified_phone_number", "Priority": 1},
+				map[string]interface{}{"Name": "verified_email", "Priority": 2},
+			},
+		},
+		"EnabledMfas": assertions.Match_Absent(),
+		"Policies":    assertions.Match_Absent(),
+	})
+	template.HasResource(jsii.String("AWS::Cognito::UserPool"), map[string]interface{}{
+		"DeletionPolicy": "Retain",
+	})
+}
+
+func TestStages(t *testing.T) {
+	tests := []struct {
+		stage          string
+		minLength      int
+		requireSymbols bool
+		mfa            string
+		enabledMfas    interface{}
+		protection     string
+		deletionPolicy string
+	}{
+		{"dev", 8, false, "OFF", assertions.Match_Absent(), "INACTIVE", "Delete"},
+		{"staging", 10, true, "OPTIONAL", []string{"SMS_MFA", "SOFTWARE_TOKEN_MFA"}, "INACTIVE", "Delete"},
+		{"prod", 12, true, "OPTIONAL", []string{"SMS_MFA", "SOFTWARE_TOKEN_MFA"}, "ACTIVE", "Retain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stage, func(t *testing.T) {
+			id, props, ok := stageStack(tt.stage)
+			if !ok || id != "InfraStack-"+tt.stage {
+				t.Fatalf("stageStack(%q) = %s, %v", tt.stage, id, ok)
+			}
+			template := newTestTemplate(t, props)
+
+			template.HasResourceProperties(jsii.String("AWS::Cognito::UserPool"), map[string]interface{}{
+				"UserPoolName": "go-cognito-" + tt.stage,
+				"Policies": map[string]interface{}{
+					"PasswordPolicy": assertions.Match_ObjectLike(&map[string]interface{}{
+						"MinimumLength":    tt.minLength,
+						"RequireLowercase": true,
+						"RequireUppercase": true,
+						"RequireNumbers":   true,
+						"RequireSymbols":   tt.requireSymbols,
+					}),
+				},
+				"MfaConfiguration":   tt.mfa,
+				"EnabledMfas":        tt.enabledMfas,
+				"DeletionProtection": tt.protection,
+				"AccountRecoverySetting": map[string]interface{}{
+					"RecoveryMechanisms": []interface{}{
+						map[string]interface{}{"Name": "verified_email", "Priority": 1},
+					},
+				},
+			})
+			template.HasResource(jsii.String("AWS::Cognito::UserPool"), map[string]interface{}{
+				"DeletionPolicy": tt.deletionPolicy,
+			})
+			template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolClient"), map[string]interface{}{
+				"ClientName": "go-cognito-" + tt.stage + "-client",
+			})
+		})
+	}
+
+	if _, _, ok := stageStack("qa"); ok {
+		t.Fatal("stageStack(qa) found, want unknown stage")
+	}
+}
+
+// Without a stage the stack deployed before stages existed is kept, with its pool and client names
+func TestDefaultStage(t *testing.T) {
+	id, props, ok := stageStack("")
+	if !ok || id != "InfraStack" {
+		t.Fatalf("stageStack(\"\") = %s, %v, want InfraStack", id, ok)
+	}
+
+	template := newTestTemplate(t, props)
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPool"), map[string]interface{}{
+		"UserPoolName": "userpool-1",
+	})
+	template.HasResource(jsii.String("AWS::Cognito::UserPool"), map[string]interface{}{
+		"DeletionPolicy": "Retain",
+	})
+	template.HasResourceProperties(jsii.String("AWS::Cognito::UserPoolClient"), map[string]interface{}{
+		"ClientName": "user-pool-client-1",
+	})
+}
+
+func TestOutputs(t *testing.T) {
+	template := newTestTemplate(t, &InfraStackProps{DomainPrefix: "go-cognito-test"})
+
+	for _, id := range []string{"UserPoolId", "ClientId", "Region", "Issuer", "JwksUrl", "ClientSecretArn", "HostedUIDomain"} {
+		template.HasOutput(jsii.String(id), map[string]interface{}{})
+	}
+
+	template.HasOutput(jsii.String("UserPoolId"), map[string]interface{}{
+		"Value": map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("^UserPool"))},
+	})
+	template.HasOutput(jsii.String("ClientSecretArn"), map[string]interface{}{
+		"Value": map[string]interface{}{"Ref": assertions.Match_StringLikeRegexp(jsii.String("^ClientSecret"))},
+	})
+
+	// Without a domain there is no hosted UI to point the service at
+	template = newTestTemplate(t, nil)
+	if outputs := template.FindOutputs(jsii.String("HostedUIDomain"), map[string]interface{}{}); len(*outputs) != 0 {
+		t.Fatalf("found HostedUIDomain output %v, want none", *outputs)
+	}
+}
+
+func TestClientSecretStoredInSecretsManager(t *testing.T) {
+	template := newTestTemplate(t, nil)
+
+	template.ResourceCountIs(jsii.String("AWS::SecretsManager::Secret"), jsii.Number(1))
+	template.HasResourceProperties(jsii.String("AWS::SecretsManager::Secret"), map[string]interface{}{
+		"SecretString": map[string]interface{}{
+			"Fn::GetAtt": assertions.Match_ArrayWith(&[]interface{}{"UserPoolClient.ClientSecret"}),
+		},
+	})
+	template.HasResource(jsii.String("AWS::SecretsManager::Secret"), map[string]interface{}{
+		"DeletionPolicy": "Retain",
+	})
+}
@@ -0,0 +1,129 @@
+// Create Auth Challenge trigger, emails a one-time code for the passwordless CUSTOM_AUTH flow
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// Prefix of the challenge metadata that carries the code into later rounds
+const codeMetadataPrefix = "CODE-"
+
+type emailSender interface {
+	SendEmail(ctx context.Context, params *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error)
+}
+
+type challengeCreator struct {
+	sender emailSender
+	// Verified SES identity the codes are sent from
+	fromAddress string
+}
+
+func (c *challengeCreator) handle(ctx context.Context, event events.CognitoEventUserPoolsCreateAuthChallenge) (events.CognitoEventUserPoolsCreateAuthChallenge, error) {
+	if event.Request.ChallengeName != "CUSTOM_CHALLENGE" {
+		return event, nil
+	}
+
+	email := event.Request.UserAttributes["email"]
+
+	// Reuse the code from the previous round so a typo does not trigger another email
+	code := previousCode(event.Request.Session)
+	if code == "" {
+		var err error
+		if code, err = newCode(); err != nil {
+			return event, err
+		}
+		if err := c.send(ctx, email, code); err != nil {
+			return event, err
+		}
+	}
+
+	event.Response.PublicChallengeParameters = map[string]string{"email": maskEmail(email)}
+	event.Response.PrivateChallengeParameters = map[string]string{"code": code}
+	event.Response.ChallengeMetadata = codeMetadataPrefix + code
+
+	return event, nil
+}
+
+func (c *challengeCreator) send(ctx context.Context, email, code string) error {
+	_, err := c.sender.SendEmail(ctx, &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(c.fromAddress),
+		Destination:      &types.Destination{ToAddresses: []string{email}},
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{Data: aws.String("Your sign-in code")},
+				Body: &types.Body{
+					Text: &types.Content{Data: aws.String(fmt.Sprintf("Your sign-in code is %s", code))},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("Could not send sign-in code: %w", err)
+	}
+
+	return nil
+}
+
+func previousCode(session []*events.CognitoEventUserPoolsChallengeResult) string {
+	if len(session) == 0 {
+		return ""
+	}
+	code, found := strings.CutPrefix(session[len(session)-1].ChallengeMetadata, codeMetadataPrefix)
+	if !found {
+		return ""
+	}
+	return code
+}
+
+func newCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// Masks the address the way Cognito reports code destinations, e.g. j***@e***
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" || domain == "" {
+		return "***"
+	}
+	return firstRune(local) + "***@" + firstRune(domain) + "***"
+}
+
+func firstRune(s string) string {
+	_, size := utf8.DecodeRuneInString(s)
+	return s[:size]
+}
+
+func main() {
+	sdkConfig, err := config.LoadDefaultConfig(context.Background())
+
+	if err != nil {
+		log.Fatalf("Couldn't load default configuration: %v", err)
+	}
+
+	creator := &challengeCreator{
+		sender:      sesv2.NewFromConfig(sdkConfig),
+		fromAddress: os.Getenv("SENDER_EMAIL"),
+	}
+
+	lambda.Start(creator.handle)
+}
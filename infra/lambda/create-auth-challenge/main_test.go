@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+)
+
+type recordingSender struct {
+	sent []*sesv2.SendEmailInput
+}
+
+func (s *recordingSender) SendEmail(ctx context.Context, params *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error) {
+	s.sent = append(s.sent, params)
+	return &sesv2.SendEmailOutput{}, nil
+}
+
+func newEvent(session ...*events.CognitoEventUserPoolsChallengeResult) events.CognitoEventUserPoolsCreateAuthChallenge {
+	var event events.CognitoEventUserPoolsCreateAuthChallenge
+	event.Request.ChallengeName = "CUSTOM_CHALLENGE"
+	event.Request.UserAttributes = map[string]string{"email": "jane@example.com"}
+	event.Request.Session = session
+	return event
+}
+
+func TestHandleSendsCode(t *testing.T) {
+	sender := &recordingSender{}
+	creator := &challengeCreator{sender: sender, fromAddress: "no-reply@example.com"}
+
+	event, err := creator.handle(context.Background(), newEvent())
+	if err != nil {
+		t.Fatalf("handle() error = %v", err)
+	}
+
+	code := event.Response.PrivateChallengeParameters["code"]
+	if len(code) != 6 || event.Response.ChallengeMetadata != codeMetadataPrefix+code {
+		t.Fatalf("unexpected response: %+v", event.Response)
+	}
+	if event.Response.PublicChallengeParameters["email"] != "j***@e***" {
+		t.Fatalf("unexpected public parameters: %+v", event.Response.PublicChallengeParameters)
+	}
+
+	if len(sender.sent) != 1 || sender.sent[0].Destination.ToAddresses[0] != "jane@example.com" {
+		t.Fatalf("unexpected emails: %+v", sender.sent)
+	}
+	if body := *sender.sent[0].Content.Simple.Body.Text.Data; !strings.Contains(body, code) {
+		t.Fatalf("email does not contain the code: %q", body)
+	}
+}
+
+func TestHandleReusesCodeOnRetry(t *testing.T) {
+	sender := &recordingSender{}
+	creator := &challengeCreator{sender: sender}
+
+	previous := &events.CognitoEventUserPoolsChallengeResult{ChallengeName: "CUSTOM_CHALLENGE", ChallengeMetadata: codeMetadataPrefix + "123456"}
+	event, err := creator.handle(context.Background(), newEvent(previous))
+	if err != nil {
+		t.Fatalf("handle() error = %v", err)
+	}
+
+	if event.Response.PrivateChallengeParameters["code"] != "123456" || len(sender.sent) != 0 {
+		t.Fatalf("expected the previous code to be reused without an email, got %+v", event.Response)
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{"ascii", "jane@example.com", "j***@e***"},
+		{"multibyte", "émile@ümlaut.de", "é***@ü***"},
+		{"no domain", "jane", "***"},
+		{"no local part", "@example.com", "***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskEmail(tt.email); got != tt.want {
+				t.Fatalf("maskEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Define Auth Challenge trigger, decides each step of the passwordless CUSTOM_AUTH flow
+
+package main
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+const customChallenge = "CUSTOM_CHALLENGE"
+
+// Wrong codes allowed before the sign in fails and a new one must be started
+const maxAttempts = 3
+
+func handler(ctx context.Context, event events.CognitoEventUserPoolsDefineAuthChallenge) (events.CognitoEventUserPoolsDefineAuthChallenge, error) {
+	var attempts int
+	var lastCorrect bool
+	for _, challenge := range event.Request.Session {
+		if challenge.ChallengeName == customChallenge {
+			attempts++
+			lastCorrect = challenge.ChallengeResult
+		}
+	}
+
+	switch {
+	case event.Request.UserNotFound:
+		event.Response.FailAuthentication = true
+	case lastCorrect:
+		event.Response.IssueTokens = true
+	case attempts >= maxAttempts:
+		event.Response.FailAuthentication = true
+	default:
+		event.Response.ChallengeName = customChallenge
+	}
+
+	return event, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandler(t *testing.T) {
+	challenge := func(correct bool) *events.CognitoEventUserPoolsChallengeResult {
+		return &events.CognitoEventUserPoolsChallengeResult{ChallengeName: customChallenge, ChallengeResult: correct}
+	}
+
+	tests := []struct {
+		name         string
+		session      []*events.CognitoEventUserPoolsChallengeResult
+		userNotFound bool
+		want         events.CognitoEventUserPoolsDefineAuthChallengeResponse
+	}{
+		{"first round", nil, false, events.CognitoEventUserPoolsDefineAuthChallengeResponse{ChallengeName: customChallenge}},
+		{"unknown user", nil, true, events.CognitoEventUserPoolsDefineAuthChallengeResponse{FailAuthentication: true}},
+		{"correct code", []*events.CognitoEventUserPoolsChallengeResult{challenge(false), challenge(true)}, false, events.CognitoEventUserPoolsDefineAuthChallengeResponse{IssueTokens: true}},
+		{"retry", []*events.CognitoEventUserPoolsChallengeResult{challenge(false)}, false, events.CognitoEventUserPoolsDefineAuthChallengeResponse{ChallengeName: customChallenge}},
+		{"out of attempts", []*events.CognitoEventUserPoolsChallengeResult{challenge(false), challenge(false), challenge(false)}, false, events.CognitoEventUserPoolsDefineAuthChallengeResponse{FailAuthentication: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event events.CognitoEventUserPoolsDefineAuthChallenge
+			event.Request.Session = tt.session
+			event.Request.UserNotFound = tt.userNotFound
+
+			got, err := handler(context.Background(), event)
+			if err != nil {
+				t.Fatalf("handler() error = %v", err)
+			}
+			if got.Response != tt.want {
+				t.Fatalf("response = %+v, want %+v", got.Response, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+// Verify Auth Challenge Response trigger, checks the code the user typed in
+
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func handler(ctx context.Context, event events.CognitoEventUserPoolsVerifyAuthChallenge) (events.CognitoEventUserPoolsVerifyAuthChallenge, error) {
+	expected := event.Request.PrivateChallengeParameters["code"]
+	answer := fmt.Sprint(event.Request.ChallengeAnswer)
+
+	event.Response.AnswerCorrect = expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(answer)) == 1
+
+	return event, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awscognito"
+	"github.com/aws/jsii-runtime-go"
+)
+
+// Stack deployed when no stage is given with -c stage=<name>. It keeps the id and settings it had before
+// stages were added, so a plain cdk deploy updates the existing user pool instead of creating another.
+const defaultStackId = "InfraStack"
+
+// Returns the id and settings of the stage's stack, the default stack when stage is empty
+func stageStack(stage string) (string, *InfraStackProps, bool) {
+	if stage == "" {
+		return defaultStackId, &InfraStackProps{}, true
+	}
+	props, ok := stageProps(stage)
+	return defaultStackId + "-" + stage, props, ok
+}
+
+// User pool settings of each deployment stage, deployed as InfraStack-<stage>
+func stageProps(stage string) (*InfraStackProps, bool) {
+	switch stage {
+	case "dev":
+		// Throwaway pool, users are lost when the stack is destroyed
+		return &InfraStackProps{
+			UserPoolName:    "go-cognito-dev",
+			ClientName:      "go-cognito-dev-client",
+			PasswordPolicy:  passwordPolicy(8, false),
+			AccountRecovery: awscognito.AccountRecovery_EMAIL_ONLY,
+			RemovalPolicy:   awscdk.RemovalPolicy_DESTROY,
+		}, true
+	case "staging":
+		return &InfraStackProps{
+			UserPoolName:    "go-cognito-staging",
+			ClientName:      "go-cognito-staging-client",
+			PasswordPolicy:  passwordPolicy(10, true),
+			Mfa:             awscognito.Mfa_OPTIONAL,
+			MfaSecondFactor: &awscognito.MfaSecondFactor{Otp: jsii.Bool(true), Sms: jsii.Bool(true)},
+			AccountRecovery: awscognito.AccountRecovery_EMAIL_ONLY,
+			RemovalPolicy:   awscdk.RemovalPolicy_DESTROY,
+		}, true
+	case "prod":
+		return &InfraStackProps{
+			UserPoolName:       "go-cognito-prod",
+			ClientName:         "go-cognito-prod-client",
+			PasswordPolicy:     passwordPolicy(12, true),
+			Mfa:                awscognito.Mfa_OPTIONAL,
+			MfaSecondFactor:    &awscognito.MfaSecondFactor{Otp: jsii.Bool(true), Sms: jsii.Bool(true)},
+			AccountRecovery:    awscognito.AccountRecovery_EMAIL_ONLY,
+			DeletionProtection: true,
+			RemovalPolicy:      awscdk.RemovalPolicy_RETAIN,
+		}, true
+	}
+	return nil, false
+}
+
+// Requires lower and upper case letters and digits, and symbols when requested
+func passwordPolicy(minLength float64, requireSymbols bool) *awscognito.PasswordPolicy {
+	return &awscognito.PasswordPolicy{
+		MinLength:        jsii.Number(minLength),
+		RequireLowercase: jsii.Bool(true),
+		RequireUppercase: jsii.Bool(true),
+		RequireDigits:    jsii.Bool(true),
+		RequireSymbols:   jsii.Bool(requireSymbols),
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsiam"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awslambda"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3assets"
+	"github.com/aws/constructs-go/constructs/v10"
+	"github.com/aws/jsii-runtime-go"
+)
+
+// Lambda triggers behind the passwordless CUSTOM_AUTH flow, built from lambda/<name>
+type authChallengeTriggers struct {
+	Define awslambda.IFunction
+	Create awslambda.IFunction
+	Verify awslambda.IFunction
+}
+
+func newAuthChallengeTriggers(scope constructs.Construct, senderEmail string) *authChallengeTriggers {
+	create := newGoFunction(scope, "CreateAuthChallenge", "create-auth-challenge", map[string]*string{
+		"SENDER_EMAIL": jsii.String(senderEmail),
+	})
+
+	// The code email is sent through SES from a verified identity
+	create.AddToRolePolicy(awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+		Actions: jsii.Strings("ses:SendEmail"),
+		Resources: jsii.Strings(*awscdk.Stack_Of(scope).FormatArn(&awscdk.ArnComponents{
+			Service:      jsii.String("ses"),
+			Resource:     jsii.String("identity"),
+			ResourceName: jsii.String("*"),
+		})),
+	}))
+
+	return &authChallengeTriggers{
+		Define: newGoFunction(scope, "DefineAuthChallenge", "define-auth-challenge", nil),
+		Create: create,
+		Verify: newGoFunction(scope, "VerifyAuthChallenge", "verify-auth-challenge", nil),
+	}
+}
+
+// Creates an arm64 function from the Go package in lambda/<name>
+func newGoFunction(scope constructs.Construct, id, name string, environment map[string]*string) awslambda.Function {
+	pkg := "./" + filepath.Join("lambda", name)
+
+	return awslambda.NewFunction(scope, jsii.String(id), &awslambda.FunctionProps{
+		Runtime:      awslambda.Runtime_PROVIDED_AL2023(),
+		Architecture: awslambda.Architecture_ARM_64(),
+		Handler:      jsii.String("bootstrap"),
+		Environment:  &environment,
+		// The whole module is the asset so go.mod is available to the build
+		Code: awslambda.Code_FromAsset(jsii.String("."), &awss3assets.AssetOptions{
+			Exclude: jsii.Strings("cdk.out", "*_test.go"),
+			Bundling: &awscdk.BundlingOptions{
+				Image: awscdk.DockerImage_FromRegistry(jsii.String("golang:1.23")),
+				Environment: &map[string]*string{
+					"CGO_ENABLED": jsii.String("0"),
+					"GOOS":        jsii.String("linux"),
+					"GOARCH":      jsii.String("arm64"),
+				},
+				Command: jsii.Strings("go", "build", "-tags", "lambda.norpc", "-o", "/asset-output/bootstrap", pkg),
+				Local:   &localGoBuild{pkg: pkg},
+			},
+		}),
+	})
+}
+
+// Builds with the local Go toolchain when it is installed, otherwise CDK falls back to Docker
+type localGoBuild struct {
+	pkg string
+}
+
+func (b *localGoBuild) TryBundle(outputDir *string, options *awscdk.BundlingOptions) *bool {
+	if _, err := exec.LookPath("go"); err != nil {
+		return jsii.Bool(false)
+	}
+
+	build := exec.Command("go", "build", "-tags", "lambda.norpc", "-o", filepath.Join(*outputDir, "bootstrap"), b.pkg)
+	build.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux", "GOARCH=arm64")
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+
+	return jsii.Bool(build.Run() == nil)
+}
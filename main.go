@@ -5,11 +5,13 @@ import (
 
 	"example.com/go-cognito/config"
 	_ "example.com/go-cognito/docs"
+	"example.com/go-cognito/emulator"
 	"example.com/go-cognito/handlers"
 	"example.com/go-cognito/middleware"
 	"example.com/go-cognito/routes"
 	"example.com/go-cognito/services"
 	"example.com/go-cognito/utils"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"     // swagger embed files
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
@@ -26,26 +28,86 @@ import (
 // @host      localhost:8080
 // @BasePath  /
 
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Access token issued by signIn, sent as "Bearer <token>"
+
 // @externalDocs.description  OpenAPI
 // @externalDocs.url          https://swagger.io/resources/open-api/
 func main() {
 	// Creates and returns configuration with environment variables
 	config := config.LoadConfig()
 
-	// Create client to interact with AWS Cognito
-	client, err := utils.CreateCognitoClient()
+	var cognitoClient services.CognitoAPI
+	var localEmulator *emulator.Emulator
+
+	if config.LocalMode {
+		// Serve Cognito from memory, tokens are verified against the emulator's own JWKS
+		var err error
+		localEmulator, err = emulator.New(config.ClientId, config.ClientSecret, services.CognitoIssuer(config.Region, config.UserPoolId))
+
+		if err != nil {
+			log.Fatalf("Failed to create Cognito emulator: %v", err)
+		}
+
+		cognitoClient = localEmulator
+		if config.JWKSURL == "" {
+			config.JWKSURL = "http://localhost:8080" + routes.LocalJWKSPath
+		}
+		log.Println("Running in local mode, confirmation codes are logged and listed at " + routes.LocalCodesPath)
+	} else {
+		// Create client to interact with AWS Cognito
+		client, err := utils.CreateCognitoClient()
 
-	if err != nil {
-		log.Fatalf("Failed to create Cognito client: %v", err)
+		if err != nil {
+			log.Fatalf("Failed to create Cognito client: %v", err)
+		}
+		cognitoClient = client.CognitoClient
 	}
 
 	// Create authService using Cognito client, client ID, and client secret
-	authService := services.NewAuthService(client.CognitoClient, config.ClientId, config.ClientSecret, config.Region, config.UserPoolId)
+	authService := services.NewAuthService(cognitoClient, config.ClientId, config.ClientSecret, config.Region, config.UserPoolId)
+	defer authService.Close()
+
+	if config.JWKSURL != "" {
+		authService.JWKSURL = config.JWKSURL
+	}
+	authService.AllowedClientIDs = append(authService.AllowedClientIDs, config.AllowedClientIds...)
+	authService.ClockSkew = config.TokenClockSkew
+	authService.AuthFlow = types.AuthFlowType(config.AuthFlow)
+	if config.TOTPIssuer != "" {
+		authService.TOTPIssuer = config.TOTPIssuer
+	}
+	if len(config.EditableAttributes) > 0 {
+		authService.EditableAttributes = config.EditableAttributes
+	}
+
 	authHandler := handlers.NewAuthHandler(authService)
+
+	// Admin operations act on the same user pool as the AuthService
+	adminService := services.NewAdminService(authService.CognitoClient, authService.UserPoolID)
+	if config.AdminPageSize > 0 {
+		adminService.PageSize = config.AdminPageSize
+	}
+	adminHandler := handlers.NewAdminHandler(adminService)
+
 	middlewareHandler := middleware.NewMiddlewareHandler(authService)
+	middlewareHandler.AcceptedTokenUses = config.AcceptedTokenUses
 
 	server := gin.Default()
-	routes.RegisterRoutes(server, middlewareHandler, authHandler)
+	routes.RegisterRoutes(server, middlewareHandler, authHandler, adminHandler)
+	if config.HostedUIDomain != "" {
+		oauthService := services.NewOAuthService(authService, config.HostedUIDomain, config.OAuthRedirectURI)
+		oauthService.LogoutURI = config.OAuthLogoutURI
+		if len(config.OAuthScopes) > 0 {
+			oauthService.Scopes = config.OAuthScopes
+		}
+		routes.RegisterOAuthRoutes(server, handlers.NewOAuthHandler(oauthService))
+	}
+	if localEmulator != nil {
+		routes.RegisterLocalRoutes(server, localEmulator)
+	}
 	server.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	server.Run(":8080")
 }
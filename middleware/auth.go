@@ -1,20 +1,31 @@
 package middleware
 
 import (
-	"net/http"
+	"fmt"
 	"strings"
 
+	"example.com/go-cognito/models"
 	"example.com/go-cognito/services"
 	"github.com/gin-gonic/gin"
 )
 
+// Keys under which Authenticate stores the verified caller in the gin context
+const (
+	claimsKey   = "claims"
+	idClaimsKey = "idClaims"
+	tokenKey    = "token"
+)
+
 type MiddlewareHandler struct {
 	Service *services.AuthService
+	// Token types accepted by Authenticate, services.TokenUseAccess and/or services.TokenUseID
+	AcceptedTokenUses []string
 }
 
 func NewMiddlewareHandler(service *services.AuthService) *MiddlewareHandler {
 	return &MiddlewareHandler{
-		Service: service,
+		Service:           service,
+		AcceptedTokenUses: []string{services.TokenUseAccess},
 	}
 }
 
@@ -22,24 +33,79 @@ func (s *MiddlewareHandler) Authenticate(context *gin.Context) {
 	token := context.Request.Header.Get("Authorization")
 
 	if token == "" {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Authorization token is required."})
+		abortUnauthorized(context, "Authorization token is required.")
 		return
 	}
 
 	token = strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))
 
-	valid, err := s.Service.VerifyToken(token)
+	tokenUse, err := services.TokenUse(token)
 
 	if err != nil {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		abortInvalidToken(context, err)
 		return
 	}
 
-	if !valid {
-		context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+	if !s.accepts(tokenUse) {
+		abortInvalidToken(context, fmt.Errorf("%w Accepted token types: %s.", services.ErrInvalidTokenUse, strings.Join(s.AcceptedTokenUses, ", ")))
 		return
 	}
 
+	// Make the caller available to downstream handlers
+	switch tokenUse {
+	case services.TokenUseID:
+		claims, err := s.Service.VerifyIDToken(token, "")
+		if err != nil {
+			abortInvalidToken(context, err)
+			return
+		}
+		context.Set(idClaimsKey, claims)
+	default:
+		claims, err := s.Service.VerifyToken(token)
+		if err != nil {
+			abortInvalidToken(context, err)
+			return
+		}
+		context.Set(claimsKey, claims)
+	}
+
+	context.Set(tokenKey, token)
+
 	context.Next()
 
 }
+
+func (s *MiddlewareHandler) accepts(tokenUse string) bool {
+	for _, accepted := range s.AcceptedTokenUses {
+		if accepted == tokenUse {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the access token claims stored by Authenticate
+func ClaimsFrom(context *gin.Context) (*models.AccessTokenClaims, bool) {
+	value, ok := context.Get(claimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*models.AccessTokenClaims)
+	return claims, ok
+}
+
+// Returns the ID token claims stored by Authenticate when the caller sent an ID token
+func IDClaimsFrom(context *gin.Context) (*models.IDTokenClaims, bool) {
+	value, ok := context.Get(idClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*models.IDTokenClaims)
+	return claims, ok
+}
+
+// Returns the raw bearer token stored by Authenticate
+func TokenFrom(context *gin.Context) (string, bool) {
+	token := context.GetString(tokenKey)
+	return token, token != ""
+}
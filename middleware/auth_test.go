@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func serveAuthenticate(handler *MiddlewareHandler, authorization string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/", handler.Authenticate, func(context *gin.Context) {
+		context.Status(http.StatusNoContent)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAuthenticateRejectsUnacceptedTokenUse(t *testing.T) {
+	handler := &MiddlewareHandler{AcceptedTokenUses: []string{services.TokenUseAccess}}
+
+	// Rejected before any signature check, so an unsigned token is enough
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"token_use": "id"}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	recorder := serveAuthenticate(handler, "Bearer "+token)
+
+	var body models.ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if recorder.Code != http.StatusUnauthorized || body.Code != CodeInvalidTokenUse {
+		t.Fatalf("got %d %+v, want 401 %s", recorder.Code, body, CodeInvalidTokenUse)
+	}
+}
+
+func TestAuthenticateRequiresToken(t *testing.T) {
+	handler := &MiddlewareHandler{AcceptedTokenUses: []string{services.TokenUseAccess}}
+
+	for _, authorization := range []string{"", "Bearer not-a-jwt"} {
+		if recorder := serveAuthenticate(handler, authorization); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("Authorization %q: status = %d, want 401", authorization, recorder.Code)
+		}
+	}
+}
+
+func TestAbortInvalidToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+		sentinel error
+	}{
+		{"expired", services.ErrTokenExpired, CodeTokenExpired, services.ErrTokenExpired},
+		{"not yet valid", services.ErrTokenNotYetValid, CodeTokenNotYetValid, services.ErrTokenNotYetValid},
+		{"issuer", services.ErrInvalidIssuer, CodeInvalidIssuer, services.ErrInvalidIssuer},
+		{"client", services.ErrInvalidClientID, CodeInvalidClient, services.ErrInvalidClientID},
+		{"audience", services.ErrInvalidAudience, CodeInvalidAudience, services.ErrInvalidAudience},
+		{"token use", fmt.Errorf("%w Must be an ID token.", services.ErrInvalidTokenUse), CodeInvalidTokenUse, services.ErrInvalidTokenUse},
+		{"nonce", services.ErrInvalidNonce, CodeInvalidNonce, services.ErrInvalidNonce},
+		{"malformed", fmt.Errorf("%w (%v)", services.ErrTokenInvalid, "token is malformed: could not base64 decode header"), CodeUnauthorized, services.ErrTokenInvalid},
+		// E.g. the JWKS could not be fetched
+		{"unmapped", errors.New("key not found"), CodeUnauthorized, services.ErrTokenInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+
+			abortInvalidToken(context, tt.err)
+
+			var body models.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if recorder.Code != http.StatusUnauthorized || body.Code != tt.wantCode || body.Message != tt.sentinel.Error() {
+				t.Fatalf("got %d %+v, want 401 %s %q", recorder.Code, body, tt.wantCode, tt.sentinel)
+			}
+		})
+	}
+}
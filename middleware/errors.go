@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Stable error codes returned by the middleware
+const (
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeForbidden    = "FORBIDDEN"
+
+	CodeInsufficientScope = "INSUFFICIENT_SCOPE"
+
+	CodeTokenExpired     = "TOKEN_EXPIRED"
+	CodeTokenNotYetValid = "TOKEN_NOT_YET_VALID"
+	CodeInvalidIssuer    = "INVALID_ISSUER"
+	CodeInvalidClient    = "INVALID_CLIENT"
+	CodeInvalidAudience  = "INVALID_AUDIENCE"
+	CodeInvalidTokenUse  = "INVALID_TOKEN_USE"
+	CodeInvalidNonce     = "INVALID_NONCE"
+)
+
+func abortWithError(context *gin.Context, status int, code, message string) {
+	context.AbortWithStatusJSON(status, models.ErrorResponse{Code: code, Message: message})
+}
+
+func abortUnauthorized(context *gin.Context, message string) {
+	abortWithError(context, http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Code for each token verification error, checked in order with errors.Is
+var tokenErrors = []struct {
+	err  error
+	code string
+}{
+	{services.ErrTokenExpired, CodeTokenExpired},
+	{services.ErrTokenNotYetValid, CodeTokenNotYetValid},
+	{services.ErrInvalidIssuer, CodeInvalidIssuer},
+	{services.ErrInvalidClientID, CodeInvalidClient},
+	{services.ErrInvalidAudience, CodeInvalidAudience},
+	{services.ErrInvalidTokenUse, CodeInvalidTokenUse},
+	{services.ErrInvalidNonce, CodeInvalidNonce},
+	{services.ErrTokenInvalid, CodeUnauthorized},
+}
+
+// Rejects a token that failed verification, telling the client why. Only the sentinel's message is
+// sent, the wrapped jwt and JWKS details stay in the server log.
+func abortInvalidToken(context *gin.Context, err error) {
+	code, message := CodeUnauthorized, services.ErrTokenInvalid.Error()
+
+	for _, tokenErr := range tokenErrors {
+		if errors.Is(err, tokenErr.err) {
+			code, message = tokenErr.code, tokenErr.err.Error()
+			break
+		}
+	}
+
+	if err.Error() != message {
+		log.Printf("Token rejected: %v", err)
+	}
+
+	abortWithError(context, http.StatusUnauthorized, code, message)
+}
+
+func abortForbidden(context *gin.Context, message string) {
+	abortWithError(context, http.StatusForbidden, CodeForbidden, message)
+}
+
+// Rejects a token that lacks the required scopes as described in RFC 6750 section 3.1
+func abortInsufficientScope(context *gin.Context, scopes []string, message string) {
+	context.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", error_description=%q, scope=%q`, message, strings.Join(scopes, " ")))
+	abortWithError(context, http.StatusForbidden, CodeInsufficientScope, message)
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Allows the request only if the caller belongs to every one of the given Cognito groups.
+// Must run after Authenticate.
+func (s *MiddlewareHandler) RequireGroups(groups ...string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		claims, ok := groupMemberFrom(context)
+		if !ok {
+			abortUnauthorized(context, "Authorization token is required.")
+			return
+		}
+
+		for _, group := range groups {
+			if !claims.InGroup(group) {
+				abortForbidden(context, fmt.Sprintf("User must belong to groups: %s.", strings.Join(groups, ", ")))
+				return
+			}
+		}
+
+		context.Next()
+	}
+}
+
+// Allows the request if the caller belongs to at least one of the given Cognito groups.
+// Must run after Authenticate.
+func (s *MiddlewareHandler) RequireAnyGroup(groups ...string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		claims, ok := groupMemberFrom(context)
+		if !ok {
+			abortUnauthorized(context, "Authorization token is required.")
+			return
+		}
+
+		for _, group := range groups {
+			if claims.InGroup(group) {
+				context.Next()
+				return
+			}
+		}
+
+		abortForbidden(context, fmt.Sprintf("User must belong to one of the groups: %s.", strings.Join(groups, ", ")))
+	}
+}
+
+// Implemented by both access and ID token claims
+type groupMember interface {
+	InGroup(group string) bool
+}
+
+func groupMemberFrom(context *gin.Context) (groupMember, bool) {
+	if claims, ok := ClaimsFrom(context); ok {
+		return claims, true
+	}
+	if claims, ok := IDClaimsFrom(context); ok {
+		return claims, true
+	}
+	return nil, false
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/go-cognito/models"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// Runs handlers behind a stub that stores the given claims, as Authenticate would
+func serveWithClaims(claims *models.AccessTokenClaims, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	router := gin.New()
+
+	chain := []gin.HandlerFunc{func(context *gin.Context) {
+		if claims != nil {
+			context.Set(claimsKey, claims)
+		}
+	}}
+	chain = append(chain, handlers...)
+	chain = append(chain, func(context *gin.Context) {
+		context.Status(http.StatusNoContent)
+	})
+	router.GET("/", chain...)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	return recorder
+}
+
+func TestRequireGroups(t *testing.T) {
+	handler := &MiddlewareHandler{}
+
+	tests := []struct {
+		name       string
+		claims     *models.AccessTokenClaims
+		middleware gin.HandlerFunc
+		wantStatus int
+	}{
+		{"all groups present", &models.AccessTokenClaims{Groups: []string{"admin", "support"}}, handler.RequireGroups("admin", "support"), http.StatusNoContent},
+		{"one group missing", &models.AccessTokenClaims{Groups: []string{"admin"}}, handler.RequireGroups("admin", "support"), http.StatusForbidden},
+		{"any group present", &models.AccessTokenClaims{Groups: []string{"support"}}, handler.RequireAnyGroup("admin", "support"), http.StatusNoContent},
+		{"no group present", &models.AccessTokenClaims{Groups: []string{"billing"}}, handler.RequireAnyGroup("admin", "support"), http.StatusForbidden},
+		{"no groups claim", &models.AccessTokenClaims{}, handler.RequireAnyGroup("admin"), http.StatusForbidden},
+		{"not authenticated", nil, handler.RequireGroups("admin"), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := serveWithClaims(tt.claims, tt.middleware)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus == http.StatusForbidden {
+				var body models.ErrorResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decode body: %v", err)
+				}
+				if body.Code != CodeForbidden || body.Message == "" {
+					t.Fatalf("unexpected error body: %+v", body)
+				}
+			}
+		})
+	}
+}
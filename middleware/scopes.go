@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/go-cognito/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Allows the request only if the access token was granted every one of the given scopes,
+// for example "orders/read". Must run after Authenticate.
+func (s *MiddlewareHandler) RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		message := fmt.Sprintf("Token must be granted scopes: %s.", strings.Join(scopes, ", "))
+
+		claims, ok := scopedClaimsFrom(context, scopes, message)
+		if !ok {
+			return
+		}
+
+		for _, scope := range scopes {
+			if !claims.HasScope(scope) {
+				abortInsufficientScope(context, scopes, message)
+				return
+			}
+		}
+
+		context.Next()
+	}
+}
+
+// Allows the request if the access token was granted at least one of the given scopes.
+// Must run after Authenticate.
+func (s *MiddlewareHandler) RequireAnyScope(scopes ...string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		message := fmt.Sprintf("Token must be granted one of the scopes: %s.", strings.Join(scopes, ", "))
+
+		claims, ok := scopedClaimsFrom(context, scopes, message)
+		if !ok {
+			return
+		}
+
+		for _, scope := range scopes {
+			if claims.HasScope(scope) {
+				context.Next()
+				return
+			}
+		}
+
+		abortInsufficientScope(context, scopes, message)
+	}
+}
+
+// Returns the access token claims stored by Authenticate. ID tokens carry no scopes,
+// so a caller authenticated with one is refused as lacking them.
+func scopedClaimsFrom(context *gin.Context, scopes []string, message string) (*models.AccessTokenClaims, bool) {
+	if claims, ok := ClaimsFrom(context); ok {
+		return claims, true
+	}
+
+	if _, ok := IDClaimsFrom(context); ok {
+		abortInsufficientScope(context, scopes, message)
+	} else {
+		abortUnauthorized(context, "Authorization token is required.")
+	}
+	return nil, false
+}
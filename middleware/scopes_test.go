@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/go-cognito/models"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireScopes(t *testing.T) {
+	handler := &MiddlewareHandler{}
+	claims := &models.AccessTokenClaims{Scope: "aws.cognito.signin.user.admin orders/read orders/write"}
+
+	tests := []struct {
+		name       string
+		claims     *models.AccessTokenClaims
+		middleware gin.HandlerFunc
+		wantStatus int
+	}{
+		{"all scopes granted", claims, handler.RequireScopes("orders/read", "orders/write"), http.StatusNoContent},
+		{"one scope missing", claims, handler.RequireScopes("orders/read", "orders/delete"), http.StatusForbidden},
+		{"any scope granted", claims, handler.RequireAnyScope("orders/delete", "orders/write"), http.StatusNoContent},
+		{"no scope granted", claims, handler.RequireAnyScope("invoices/read"), http.StatusForbidden},
+		{"scope prefix is not a match", claims, handler.RequireScopes("orders"), http.StatusForbidden},
+		{"not authenticated", nil, handler.RequireScopes("orders/read"), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := serveWithClaims(tt.claims, tt.middleware)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			challenge := recorder.Header().Get("WWW-Authenticate")
+			if tt.wantStatus == http.StatusForbidden && !strings.HasPrefix(challenge, `Bearer error="insufficient_scope"`) {
+				t.Fatalf("WWW-Authenticate = %q, want insufficient_scope challenge", challenge)
+			}
+			if tt.wantStatus != http.StatusForbidden && challenge != "" {
+				t.Fatalf("unexpected WWW-Authenticate header %q", challenge)
+			}
+		})
+	}
+}
+
+func TestRequireScopesChallengeListsScopes(t *testing.T) {
+	handler := &MiddlewareHandler{}
+	recorder := serveWithClaims(&models.AccessTokenClaims{}, handler.RequireScopes("orders/read", "orders/write"))
+
+	if challenge := recorder.Header().Get("WWW-Authenticate"); !strings.Contains(challenge, `scope="orders/read orders/write"`) {
+		t.Fatalf("WWW-Authenticate = %q, want required scopes listed", challenge)
+	}
+}
+
+func TestRequireScopesRejectsIDTokens(t *testing.T) {
+	handler := &MiddlewareHandler{}
+
+	for _, middleware := range []gin.HandlerFunc{handler.RequireScopes("orders/read"), handler.RequireAnyScope("orders/read")} {
+		router := gin.New()
+		router.GET("/", func(context *gin.Context) {
+			context.Set(idClaimsKey, &models.IDTokenClaims{})
+		}, middleware, func(context *gin.Context) {
+			context.Status(http.StatusNoContent)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		challenge := recorder.Header().Get("WWW-Authenticate")
+		if recorder.Code != http.StatusForbidden || !strings.HasPrefix(challenge, `Bearer error="insufficient_scope"`) {
+			t.Fatalf("got %d with WWW-Authenticate %q, want 403 insufficient_scope", recorder.Code, challenge)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package models
+
+import "time"
+
+// A user of the pool as seen by administrators
+type User struct {
+	Username      string `json:"username"`
+	Sub           string `json:"sub,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"emailVerified"`
+	Name          string `json:"name,omitempty"`
+	PhoneNumber   string `json:"phoneNumber,omitempty"`
+	// Cognito user status, e.g. CONFIRMED, UNCONFIRMED or FORCE_CHANGE_PASSWORD
+	Status    string    `json:"status"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Attributes without a field above, e.g. custom:team
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Only returned when fetching a single user
+	EnabledMFA []string `json:"enabledMfa,omitempty"`
+}
+
+type AdminCreateUserInput struct {
+	UserName string `json:"username" binding:"required,email"`
+	Name     string `json:"name" binding:"required"`
+	// Optional, in E.164 format, e.g. +14155550100
+	PhoneNumber string `json:"phoneNumber" binding:"omitempty,e164"`
+	// Optional, Cognito generates one when empty. The user must replace it on first sign in.
+	TemporaryPassword string `json:"temporaryPassword"`
+	// Skips the invitation email, the temporary password must then be shared another way
+	SuppressInvitation bool `json:"suppressInvitation"`
+}
+
+type AdminSetPasswordInput struct {
+	Password string `json:"password" binding:"required"`
+	// A temporary password must be replaced on the next sign in
+	Permanent bool `json:"permanent"`
+}
+
+// Query parameters of the user search. At most one of the filters can be set, Cognito
+// evaluates a single filter expression per request.
+type ListUsersInput struct {
+	Email       string `form:"email"`
+	Name        string `form:"name"`
+	Username    string `form:"username"`
+	PhoneNumber string `form:"phoneNumber"`
+	Status      string `form:"status" binding:"omitempty,oneof=UNCONFIRMED CONFIRMED ARCHIVED COMPROMISED UNKNOWN RESET_REQUIRED FORCE_CHANGE_PASSWORD EXTERNAL_PROVIDER"`
+	// Matches values starting with the filter instead of equal to it, not supported for status
+	Prefix bool `form:"prefix"`
+	// Page size, defaults to the service's page size
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=60"`
+	// Token returned with the previous page
+	NextToken string `form:"nextToken"`
+}
+
+// A page of users, NextToken is empty on the last page
+type UserPage struct {
+	Users     []User `json:"users"`
+	NextToken string `json:"nextToken,omitempty"`
+}
+
+// Pagination query parameters of the list endpoints
+type PageInput struct {
+	// Page size, defaults to the service's page size
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=60"`
+	// Token returned with the previous page
+	NextToken string `form:"nextToken"`
+}
+
+// A Cognito group, members get its name in the cognito:groups claim
+type Group struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Lower values take priority when a user's groups map to different IAM roles
+	Precedence *int32    `json:"precedence,omitempty"`
+	RoleArn    string    `json:"roleArn,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// A page of groups, NextToken is empty on the last page
+type GroupPage struct {
+	Groups    []Group `json:"groups"`
+	NextToken string  `json:"nextToken,omitempty"`
+}
+
+type CreateGroupInput struct {
+	Name        string `json:"name" binding:"required,max=128"`
+	Description string `json:"description" binding:"max=2048"`
+	Precedence  *int32 `json:"precedence" binding:"omitempty,min=0"`
+	// IAM role assumed through identity pools by members of the group
+	RoleArn string `json:"roleArn"`
+}
+
+// Changes to a group, fields left out are unchanged
+type UpdateGroupInput struct {
+	Description *string `json:"description" binding:"omitempty,max=2048"`
+	Precedence  *int32  `json:"precedence" binding:"omitempty,min=0"`
+	RoleArn     *string `json:"roleArn"`
+}
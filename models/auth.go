@@ -4,12 +4,20 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 )
 
+// Tokens issued after a successful sign in. When Cognito requires another step the tokens are
+// empty and the challenge fields are set instead, answer it at /auth/respondToChallenge.
 type AuthResponse struct {
-	AccessToken  string `json:"accessToken"`
-	IdToken      string `json:"idToken"`
-	RefreshToken string `json:"refreshToken"`
-	TokenType    string `json:"tokenType"`
-	ExpiresIn    int32  `json:"expiresIn"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	IdToken      string `json:"idToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	TokenType    string `json:"tokenType,omitempty"`
+	ExpiresIn    int32  `json:"expiresIn,omitempty"`
+
+	ChallengeName       string            `json:"challengeName,omitempty"`
+	Session             string            `json:"session,omitempty"`
+	ChallengeParameters map[string]string `json:"challengeParameters,omitempty"`
+	// Masked phone number the SMS_MFA code was sent to, e.g. +*******0100
+	CodeDeliveryDestination string `json:"codeDeliveryDestination,omitempty"`
 }
 
 func NewAuthResponse(accessToken, idToken, refreshToken, tokenType *string, expiresIn int32) AuthResponse {
@@ -21,3 +29,13 @@ func NewAuthResponse(accessToken, idToken, refreshToken, tokenType *string, expi
 		ExpiresIn:    expiresIn,
 	}
 }
+
+func NewChallengeResponse(challengeName string, session *string, challengeParameters map[string]string) AuthResponse {
+	return AuthResponse{
+		ChallengeName:       challengeName,
+		Session:             aws.ToString(session),
+		ChallengeParameters: challengeParameters,
+
+		CodeDeliveryDestination: challengeParameters["CODE_DELIVERY_DESTINATION"],
+	}
+}
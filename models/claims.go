@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims carried by a Cognito access token
+type AccessTokenClaims struct {
+	Username string   `json:"username"`
+	ClientID string   `json:"client_id"`
+	Scope    string   `json:"scope"`
+	Groups   []string `json:"cognito:groups"`
+	AuthTime int64    `json:"auth_time"`
+	TokenUse string   `json:"token_use"`
+	jwt.RegisteredClaims
+}
+
+// Returns true if the caller belongs to the given Cognito group
+func (c *AccessTokenClaims) InGroup(group string) bool {
+	for _, g := range c.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the OAuth2 scopes granted to the token
+func (c *AccessTokenClaims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// Returns true if the token was granted the given OAuth2 scope
+func (c *AccessTokenClaims) HasScope(scope string) bool {
+	for _, s := range c.Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims carried by a Cognito ID token
+type IDTokenClaims struct {
+	Username      string   `json:"cognito:username"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Name          string   `json:"name"`
+	Groups        []string `json:"cognito:groups"`
+	AuthTime      int64    `json:"auth_time"`
+	Nonce         string   `json:"nonce"`
+	TokenUse      string   `json:"token_use"`
+	jwt.RegisteredClaims
+}
+
+// Returns true if the user belongs to the given Cognito group
+func (c *IDTokenClaims) InGroup(group string) bool {
+	for _, g := range c.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
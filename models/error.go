@@ -0,0 +1,9 @@
+package models
+
+// Error body returned by every failed request
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Present when the error has several causes, e.g. password policy violations
+	Details []string `json:"details,omitempty"`
+}
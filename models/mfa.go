@@ -0,0 +1,27 @@
+package models
+
+// Secret for an authenticator app, returned when enrolling a software token
+type SoftwareTokenResponse struct {
+	SecretCode string `json:"secretCode"`
+	// Scan as a QR code to add the account to an authenticator app
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+type VerifySoftwareTokenInput struct {
+	Code string `json:"code" binding:"required,numeric,len=6"`
+	// Name shown for the device, e.g. "Jane's phone"
+	DeviceName string `json:"deviceName"`
+}
+
+// Whether an MFA method is used and whether it is the one asked for at sign in
+type MFASettings struct {
+	Enabled   bool `json:"enabled"`
+	Preferred bool `json:"preferred"`
+}
+
+// Methods left out are not changed, at least one must be given
+type MFAPreferenceInput struct {
+	SoftwareToken *MFASettings `json:"softwareToken" binding:"required_without=SMS"`
+	// Requires a phone number on the account
+	SMS *MFASettings `json:"sms" binding:"required_without=SoftwareToken"`
+}
@@ -0,0 +1,18 @@
+package models
+
+type AuthorizeInput struct {
+	// Identity provider to sign in with, e.g. Google, empty shows the hosted UI sign in page
+	Provider string `form:"provider"`
+}
+
+// Query parameters the hosted UI redirects back to the callback with
+type OAuthCallbackInput struct {
+	State string `form:"state" binding:"required"`
+	// Set on success
+	Code string `form:"code" binding:"required_without=Error"`
+	// Set instead of a code when the sign in failed, e.g. access_denied
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+	// State the browser kept from /auth/authorize, must match State
+	BrowserState string `form:"-"`
+}
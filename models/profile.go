@@ -0,0 +1,33 @@
+package models
+
+// The signed in user's profile as stored in Cognito
+type UserProfile struct {
+	Username   string            `json:"username"`
+	Attributes map[string]string `json:"attributes"`
+	// MFA methods in use, e.g. SOFTWARE_TOKEN_MFA, and the one asked for first
+	EnabledMFA   []string `json:"enabledMfa,omitempty"`
+	PreferredMFA string   `json:"preferredMfa,omitempty"`
+}
+
+// Changes to the profile, at least one of the fields must be given.
+// Changing email or phone_number sends a verification code to the new address.
+type UpdateProfileInput struct {
+	Attributes       map[string]string `json:"attributes" binding:"required_without=DeleteAttributes"`
+	DeleteAttributes []string          `json:"deleteAttributes" binding:"required_without=Attributes"`
+}
+
+type VerifyAttributeInput struct {
+	Attribute string `json:"attribute" binding:"required,oneof=email phone_number"`
+	Code      string `json:"code" binding:"required"`
+}
+
+type AttributeVerificationCodeInput struct {
+	Attribute string `json:"attribute" binding:"required,oneof=email phone_number"`
+}
+
+type ChangePasswordInput struct {
+	PreviousPassword string `json:"previousPassword" binding:"required"`
+	ProposedPassword string `json:"proposedPassword" binding:"required"`
+	// Revokes every other session. The caller's tokens are revoked too, so new ones are issued.
+	SignOutOtherSessions bool `json:"signOutOtherSessions"`
+}
@@ -4,6 +4,8 @@ type SignUpInput struct {
 	UserName string `json:"username" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 	Name     string `json:"name" binding:"required"`
+	// Optional, in E.164 format, e.g. +14155550100. Needed for SMS MFA.
+	PhoneNumber string `json:"phoneNumber" binding:"omitempty,e164"`
 }
 
 type SignInInput struct {
@@ -33,3 +35,20 @@ type RefreshTokenInput struct {
 type SignOutInput struct {
 	AccessToken string `json:"accessToken" binding:"required"`
 }
+
+type PasswordlessSignInInput struct {
+	UserName string `json:"username" binding:"required,email"`
+}
+
+type RespondToChallengeInput struct {
+	UserName      string `json:"username" binding:"required"`
+	ChallengeName string `json:"challengeName" binding:"required"`
+	Session       string `json:"session" binding:"required"`
+	// Answers NEW_PASSWORD_REQUIRED
+	NewPassword string `json:"newPassword"`
+	// Required attributes requested with NEW_PASSWORD_REQUIRED, e.g. name
+	Attributes map[string]string `json:"attributes"`
+	// Answers SOFTWARE_TOKEN_MFA with the code shown by the authenticator app, SMS_MFA with the texted code
+	// or CUSTOM_CHALLENGE with the emailed sign-in code
+	Code string `json:"code"`
+}
@@ -5,15 +5,21 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"example.com/go-cognito/emulator"
 	"example.com/go-cognito/handlers"
 	"example.com/go-cognito/middleware"
 )
 
-func RegisterRoutes(server *gin.Engine, middlewareHandler *middleware.MiddlewareHandler, authHandler *handlers.AuthHandler) {
+// Cognito group whose members may call the /admin routes
+const AdminGroupName = "admin"
+
+func RegisterRoutes(server *gin.Engine, middlewareHandler *middleware.MiddlewareHandler, authHandler *handlers.AuthHandler, adminHandler *handlers.AdminHandler) {
 	authGroup := server.Group("/auth")
 	{
 		authGroup.POST("/signUp", authHandler.SignUp)
 		authGroup.POST("/signIn", authHandler.SignIn)
+		authGroup.POST("/startPasswordlessSignIn", authHandler.StartPasswordlessSignIn)
+		authGroup.POST("/respondToChallenge", authHandler.RespondToChallenge)
 		authGroup.POST("/confirmAccount", authHandler.ConfirmAccount)
 		authGroup.POST("/forgotPassword", authHandler.ForgotPassword)
 		authGroup.POST("/confirmForgotPassword", authHandler.ConfirmForgotPassword)
@@ -25,6 +31,54 @@ func RegisterRoutes(server *gin.Engine, middlewareHandler *middleware.Middleware
 	authenticated := server.Group("/")
 	authenticated.Use(middlewareHandler.Authenticate)
 	authenticated.GET("/health", health)
+
+	mfaGroup := authenticated.Group("/mfa")
+	{
+		mfaGroup.POST("/associateSoftwareToken", authHandler.AssociateSoftwareToken)
+		mfaGroup.POST("/verifySoftwareToken", authHandler.VerifySoftwareToken)
+		mfaGroup.PUT("/preference", authHandler.SetMFAPreference)
+	}
+
+	profileGroup := authenticated.Group("/me")
+	{
+		profileGroup.GET("", authHandler.GetProfile)
+		profileGroup.PATCH("", authHandler.UpdateProfile)
+		profileGroup.DELETE("", authHandler.DeleteProfile)
+		profileGroup.POST("/password", authHandler.ChangePassword)
+		profileGroup.POST("/verifyAttribute", authHandler.VerifyAttribute)
+		profileGroup.POST("/attributeVerificationCode", authHandler.ResendAttributeVerificationCode)
+	}
+
+	// Routes below additionally require membership in the admin Cognito group
+	adminGroup := authenticated.Group("/admin")
+	adminGroup.Use(middlewareHandler.RequireAnyGroup(AdminGroupName))
+	adminGroup.GET("/health", health)
+
+	usersGroup := adminGroup.Group("/users")
+	{
+		usersGroup.GET("", adminHandler.ListUsers)
+		usersGroup.POST("", adminHandler.CreateUser)
+		usersGroup.GET("/:username", adminHandler.GetUser)
+		usersGroup.DELETE("/:username", adminHandler.DeleteUser)
+		usersGroup.POST("/:username/disable", adminHandler.DisableUser)
+		usersGroup.POST("/:username/enable", adminHandler.EnableUser)
+		usersGroup.POST("/:username/resetPassword", adminHandler.ResetUserPassword)
+		usersGroup.PUT("/:username/password", adminHandler.SetUserPassword)
+		usersGroup.POST("/:username/signOut", adminHandler.SignOutUser)
+		usersGroup.GET("/:username/groups", adminHandler.ListGroupsForUser)
+		usersGroup.PUT("/:username/groups/:groupName", adminHandler.AddUserToGroup)
+		usersGroup.DELETE("/:username/groups/:groupName", adminHandler.RemoveUserFromGroup)
+	}
+
+	groupsGroup := adminGroup.Group("/groups")
+	{
+		groupsGroup.GET("", adminHandler.ListGroups)
+		groupsGroup.POST("", adminHandler.CreateGroup)
+		groupsGroup.GET("/:groupName", adminHandler.GetGroup)
+		groupsGroup.PATCH("/:groupName", adminHandler.UpdateGroup)
+		groupsGroup.DELETE("/:groupName", adminHandler.DeleteGroup)
+		groupsGroup.GET("/:groupName/users", adminHandler.ListUsersInGroup)
+	}
 }
 
 func health(context *gin.Context) {
@@ -32,3 +86,25 @@ func health(context *gin.Context) {
 		"message": "Hello World!",
 	})
 }
+
+// Sign in through the Cognito hosted UI, registered when a hosted UI domain is configured
+func RegisterOAuthRoutes(server *gin.Engine, oauthHandler *handlers.OAuthHandler) {
+	authGroup := server.Group("/auth")
+	{
+		authGroup.GET("/authorize", oauthHandler.Authorize)
+		authGroup.GET("/callback", oauthHandler.Callback)
+		authGroup.GET("/logout", oauthHandler.Logout)
+	}
+}
+
+// Paths served by the Cognito emulator in local mode
+const (
+	LocalJWKSPath  = "/local/.well-known/jwks.json"
+	LocalCodesPath = "/local/codes"
+)
+
+// Exposes the emulator's signing keys and the confirmation codes it would have emailed
+func RegisterLocalRoutes(server *gin.Engine, localEmulator *emulator.Emulator) {
+	server.GET(LocalJWKSPath, localEmulator.JWKS)
+	server.GET(LocalCodesPath, localEmulator.Codes)
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Manages any user of the pool with the Admin* operations, for support staff
+type AdminService struct {
+	CognitoClient CognitoAdminAPI
+	UserPoolID    string
+	// Users per page when a search does not set a limit, at most 60
+	PageSize int32
+}
+
+// Cognito returns at most 60 users per ListUsers call
+const (
+	defaultPageSize = 20
+	maxPageSize     = 60
+)
+
+// Create with the client and UserPoolID of the AuthService serving the same pool
+func NewAdminService(client CognitoAdminAPI, userPoolId string) *AdminService {
+	if isNilClient(client) {
+		log.Fatalf("Cognito Client cannot be nil.")
+	}
+
+	if userPoolId == "" {
+		log.Fatalf("User Pool ID cannot be empty.")
+	}
+
+	return &AdminService{
+		CognitoClient: client,
+		UserPoolID:    userPoolId,
+		PageSize:      defaultPageSize,
+	}
+}
+
+// Creates a confirmed user who must choose a new password on first sign in
+func (s *AdminService) CreateUser(context context.Context, input models.AdminCreateUserInput) (models.User, error) {
+	// Admins vouch for the email address, so users can reset a forgotten password right away
+	attributes := []types.AttributeType{
+		{Name: aws.String("email"), Value: aws.String(input.UserName)},
+		{Name: aws.String("email_verified"), Value: aws.String("true")},
+		{Name: aws.String("name"), Value: aws.String(input.Name)},
+	}
+	if input.PhoneNumber != "" {
+		attributes = append(attributes, types.AttributeType{Name: aws.String("phone_number"), Value: aws.String(input.PhoneNumber)})
+	}
+
+	createUserInput := &cognitoidentityprovider.AdminCreateUserInput{
+		UserPoolId:             aws.String(s.UserPoolID),
+		Username:               aws.String(input.UserName),
+		UserAttributes:         attributes,
+		DesiredDeliveryMediums: []types.DeliveryMediumType{types.DeliveryMediumTypeEmail},
+	}
+	if input.TemporaryPassword != "" {
+		createUserInput.TemporaryPassword = aws.String(input.TemporaryPassword)
+	}
+	if input.SuppressInvitation {
+		createUserInput.MessageAction = types.MessageActionTypeSuppress
+	}
+
+	output, err := s.CognitoClient.AdminCreateUser(context, createUserInput)
+
+	if err != nil {
+		return models.User{}, cognitoError(fmt.Sprintf("Could not create user %s", input.UserName), err)
+	}
+
+	return newUser(output.User.Username, output.User.Attributes, output.User.Enabled, output.User.UserStatus, output.User.UserCreateDate, output.User.UserLastModifiedDate), nil
+}
+
+func (s *AdminService) GetUser(context context.Context, username string) (models.User, error) {
+	output, err := s.CognitoClient.AdminGetUser(context, &cognitoidentityprovider.AdminGetUserInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+	})
+
+	if err != nil {
+		return models.User{}, cognitoError(fmt.Sprintf("Could not get user %s", username), err)
+	}
+
+	user := newUser(output.Username, output.UserAttributes, output.Enabled, output.UserStatus, output.UserCreateDate, output.UserLastModifiedDate)
+	user.EnabledMFA = output.UserMFASettingList
+
+	return user, nil
+}
+
+// Searches users with at most one filter. Cognito's pagination token is handed out base64 encoded
+// so clients treat it as opaque and can pass it in a query string.
+func (s *AdminService) ListUsers(context context.Context, input models.ListUsersInput) (models.UserPage, error) {
+	filter, err := listUsersFilter(input)
+	if err != nil {
+		return models.UserPage{}, err
+	}
+
+	token, err := decodePageToken(input.NextToken)
+	if err != nil {
+		return models.UserPage{}, err
+	}
+
+	listUsersInput := &cognitoidentityprovider.ListUsersInput{
+		UserPoolId:      aws.String(s.UserPoolID),
+		Limit:           s.pageLimit(input.Limit),
+		PaginationToken: token,
+	}
+	if filter != "" {
+		listUsersInput.Filter = aws.String(filter)
+	}
+
+	output, err := s.CognitoClient.ListUsers(context, listUsersInput)
+
+	if err != nil {
+		return models.UserPage{}, cognitoError("Could not list users", err)
+	}
+
+	return newUserPage(output.Users, output.PaginationToken), nil
+}
+
+// Blocks sign in and token refresh, issued access tokens stay valid until they expire
+func (s *AdminService) DisableUser(context context.Context, username string) error {
+	_, err := s.CognitoClient.AdminDisableUser(context, &cognitoidentityprovider.AdminDisableUserInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+	})
+
+	if err != nil {
+		return cognitoError(fmt.Sprintf("Could not disable user %s", username), err)
+	}
+
+	return nil
+}
+
+func (s *AdminService) EnableUser(context context.Context, username string) error {
+	_, err := s.CognitoClient.AdminEnableUser(context, &cognitoidentityprovider.AdminEnableUserInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+	})
+
+	if err != nil {
+		return cognitoError(fmt.Sprintf("Could not enable user %s", username), err)
+	}
+
+	return nil
+}
+
+// Invalidates the password and emails a code, the user finishes at /auth/confirmForgotPassword
+func (s *AdminService) ResetUserPassword(context context.Context, username string) error {
+	_, err := s.CognitoClient.AdminResetUserPassword(context, &cognitoidentityprovider.AdminResetUserPasswordInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+	})
+
+	if err != nil {
+		return cognitoError(fmt.Sprintf("Could not reset password for user %s", username), err)
+	}
+
+	return nil
+}
+
+func (s *AdminService) SetUserPassword(context context.Context, username string, input models.AdminSetPasswordInput) error {
+	_, err := s.CognitoClient.AdminSetUserPassword(context, &cognitoidentityprovider.AdminSetUserPasswordInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+		Password:   aws.String(input.Password),
+		Permanent:  input.Permanent,
+	})
+
+	if err != nil {
+		return cognitoError(fmt.Sprintf("Could not set password for user %s", username), err)
+	}
+
+	return nil
+}
+
+func (s *AdminService) DeleteUser(context context.Context, username string) error {
+	_, err := s.CognitoClient.AdminDeleteUser(context, &cognitoidentityprovider.AdminDeleteUserInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+	})
+
+	if err != nil {
+		return cognitoError(fmt.Sprintf("Could not delete user %s", username), err)
+	}
+
+	return nil
+}
+
+// Membership shows up in the cognito:groups claim of tokens issued afterwards
+func (s *AdminService) AddUserToGroup(context context.Context, username, groupName string) error {
+	_, err := s.CognitoClient.AdminAddUserToGroup(context, &cognitoidentityprovider.AdminAddUserToGroupInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+		GroupName:  aws.String(groupName),
+	})
+
+	if err != nil {
+		return groupError(fmt.Sprintf("Could not add user %s to group %s", username, groupName), err)
+	}
+
+	return nil
+}
+
+func (s *AdminService) RemoveUserFromGroup(context context.Context, username, groupName string) error {
+	_, err := s.CognitoClient.AdminRemoveUserFromGroup(context, &cognitoidentityprovider.AdminRemoveUserFromGroupInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+		GroupName:  aws.String(groupName),
+	})
+
+	if err != nil {
+		return groupError(fmt.Sprintf("Could not remove user %s from group %s", username, groupName), err)
+	}
+
+	return nil
+}
+
+// Revokes the user's refresh tokens on every device
+func (s *AdminService) SignOutUser(context context.Context, username string) error {
+	_, err := s.CognitoClient.AdminUserGlobalSignOut(context, &cognitoidentityprovider.AdminUserGlobalSignOutInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+	})
+
+	if err != nil {
+		return cognitoError(fmt.Sprintf("Could not sign out user %s", username), err)
+	}
+
+	return nil
+}
+
+// Like cognitoError, but reads ResourceNotFoundException as a missing group. Elsewhere it means
+// a misconfigured pool or client, so it is only translated for operations naming a group.
+func groupError(context string, err error) error {
+	var resourceNotFound *types.ResourceNotFoundException
+	if errors.As(err, &resourceNotFound) {
+		return &AuthError{Kind: ErrGroupNotFound, Message: aws.ToString(resourceNotFound.Message), Err: err}
+	}
+	return cognitoError(context, err)
+}
+
+// Page size for a request, the service's default when the limit is not set
+func (s *AdminService) pageLimit(limit int32) *int32 {
+	if limit <= 0 {
+		limit = s.PageSize
+	}
+	return aws.Int32(min(limit, maxPageSize))
+}
+
+// Cognito tokens are base64 encoded for clients, which treat them as opaque
+func encodePageToken(token *string) string {
+	if aws.ToString(token) == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(*token))
+}
+
+func decodePageToken(token string) (*string, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, &AuthError{Kind: ErrInvalidParameter, Message: "Invalid pagination token."}
+	}
+	return aws.String(string(decoded)), nil
+}
+
+func newUserPage(users []types.UserType, nextToken *string) models.UserPage {
+	page := models.UserPage{Users: make([]models.User, 0, len(users)), NextToken: encodePageToken(nextToken)}
+	for _, user := range users {
+		page.Users = append(page.Users, newUser(user.Username, user.Attributes, user.Enabled, user.UserStatus, user.UserCreateDate, user.UserLastModifiedDate))
+	}
+	return page
+}
+
+// Builds the ListUsers filter expression, e.g. email ^= "jane", from the search parameters
+func listUsersFilter(input models.ListUsersInput) (string, error) {
+	filters := []struct{ attribute, value string }{
+		{"email", input.Email},
+		{"name", input.Name},
+		{"username", input.Username},
+		{"phone_number", input.PhoneNumber},
+		{"cognito:user_status", input.Status},
+	}
+
+	var attribute, value string
+	var given []string
+	for _, filter := range filters {
+		if filter.value != "" {
+			attribute, value = filter.attribute, filter.value
+			given = append(given, filter.attribute)
+		}
+	}
+
+	switch {
+	case len(given) == 0:
+		return "", nil
+	case len(given) > 1:
+		return "", &AuthError{Kind: ErrInvalidParameter, Message: "Only one filter can be used at a time.", Details: given}
+	}
+
+	operator := "="
+	if input.Prefix {
+		if attribute == "cognito:user_status" {
+			return "", &AuthError{Kind: ErrInvalidParameter, Message: "Status can only be matched exactly."}
+		}
+		operator = "^="
+	}
+
+	// Quotes and backslashes inside the value are escaped with a backslash
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+
+	return fmt.Sprintf(`%s %s "%s"`, attribute, operator, value), nil
+}
+
+// Projects Cognito's attribute list onto the User model
+func newUser(username *string, attributes []types.AttributeType, enabled bool, status types.UserStatusType, createdAt, updatedAt *time.Time) models.User {
+	user := models.User{
+		Username:  aws.ToString(username),
+		Status:    string(status),
+		Enabled:   enabled,
+		CreatedAt: aws.ToTime(createdAt),
+		UpdatedAt: aws.ToTime(updatedAt),
+	}
+
+	for _, attribute := range attributes {
+		name, value := aws.ToString(attribute.Name), aws.ToString(attribute.Value)
+
+		switch name {
+		case "sub":
+			user.Sub = value
+		case "email":
+			user.Email = value
+		case "email_verified":
+			user.EmailVerified = value == "true"
+		case "name":
+			user.Name = value
+		case "phone_number":
+			user.PhoneNumber = value
+		default:
+			if user.Attributes == nil {
+				user.Attributes = make(map[string]string)
+			}
+			user.Attributes[name] = value
+		}
+	}
+
+	return user
+}
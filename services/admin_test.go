@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Returns an AuthService and AdminService sharing a fake pool
+func newFakeAdminService(t *testing.T) (*AdminService, *AuthService, *cognitofake.Client) {
+	t.Helper()
+
+	service, fake := newFakeAuthService(t)
+	fake.UserPoolID = service.UserPoolID
+
+	return NewAdminService(fake, service.UserPoolID), service, fake
+}
+
+func TestAdminCreateUser(t *testing.T) {
+	admin, service, fake := newFakeAdminService(t)
+	ctx := context.Background()
+
+	var invitation string
+	fake.CodeSender = func(user cognitofake.User, purpose, code string) {
+		if purpose == cognitofake.CodeInvitation {
+			invitation = code
+		}
+	}
+
+	user, err := admin.CreateUser(ctx, models.AdminCreateUserInput{UserName: testUsername, Name: "Jane", PhoneNumber: "+14155550100"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	if user.Username != testUsername || user.Email != testUsername || !user.EmailVerified || user.Name != "Jane" ||
+		user.PhoneNumber != "+14155550100" || user.Status != "FORCE_CHANGE_PASSWORD" || !user.Enabled || user.Sub == "" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	if invitation == "" {
+		t.Fatal("no invitation sent")
+	}
+
+	response, err := service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: invitation})
+	if err != nil || response.ChallengeName != "NEW_PASSWORD_REQUIRED" {
+		t.Fatalf("SignIn() = %+v, %v, want NEW_PASSWORD_REQUIRED", response, err)
+	}
+
+	if _, err := admin.CreateUser(ctx, models.AdminCreateUserInput{UserName: testUsername, Name: "Jane"}); !errors.Is(err, ErrUsernameExists) {
+		t.Fatalf("CreateUser() duplicate error = %v, want %v", err, ErrUsernameExists)
+	}
+}
+
+func TestAdminDisableUser(t *testing.T) {
+	admin, service, fake := newFakeAdminService(t)
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+	ctx := context.Background()
+
+	if err := admin.DisableUser(ctx, testUsername); err != nil {
+		t.Fatalf("DisableUser() error = %v", err)
+	}
+
+	if _, err := service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: testPassword}); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("SignIn() disabled error = %v, want %v", err, ErrNotAuthorized)
+	}
+	if _, err := service.GetTokensFromRefreshToken(ctx, models.RefreshTokenInput{RefreshToken: tokens.RefreshToken}); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("GetTokensFromRefreshToken() disabled error = %v, want %v", err, ErrNotAuthorized)
+	}
+
+	user, err := admin.GetUser(ctx, testUsername)
+	if err != nil || user.Enabled {
+		t.Fatalf("GetUser() = %+v, %v, want disabled user", user, err)
+	}
+
+	if err := admin.EnableUser(ctx, testUsername); err != nil {
+		t.Fatalf("EnableUser() error = %v", err)
+	}
+	signIn(t, service, testPassword)
+}
+
+func TestAdminPasswords(t *testing.T) {
+	admin, service, fake := newFakeAdminService(t)
+	signUpConfirmed(t, service, fake)
+	ctx := context.Background()
+
+	if err := admin.ResetUserPassword(ctx, testUsername); err != nil {
+		t.Fatalf("ResetUserPassword() error = %v", err)
+	}
+
+	if _, err := service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: testPassword}); !errors.Is(err, ErrPasswordResetRequired) {
+		t.Fatalf("SignIn() after reset error = %v, want %v", err, ErrPasswordResetRequired)
+	}
+
+	if _, ok := fake.Code(testUsername, cognitofake.CodeForgotPassword); !ok {
+		t.Fatal("no reset code sent")
+	}
+
+	// A temporary password has to be replaced at sign in
+	if err := admin.SetUserPassword(ctx, testUsername, models.AdminSetPasswordInput{Password: "Password-456"}); err != nil {
+		t.Fatalf("SetUserPassword() error = %v", err)
+	}
+	if response := signIn(t, service, "Password-456"); response.ChallengeName != "NEW_PASSWORD_REQUIRED" {
+		t.Fatalf("SignIn() = %+v, want NEW_PASSWORD_REQUIRED", response)
+	}
+
+	if err := admin.SetUserPassword(ctx, testUsername, models.AdminSetPasswordInput{Password: "Password-789", Permanent: true}); err != nil {
+		t.Fatalf("SetUserPassword() permanent error = %v", err)
+	}
+	if response := signIn(t, service, "Password-789"); response.AccessToken == "" {
+		t.Fatalf("SignIn() = %+v, want tokens", response)
+	}
+
+	if err := admin.SetUserPassword(ctx, testUsername, models.AdminSetPasswordInput{Password: "weak"}); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("SetUserPassword() weak error = %v, want %v", err, ErrInvalidPassword)
+	}
+}
+
+func TestAdminGroups(t *testing.T) {
+	admin, service, fake := newFakeAdminService(t)
+	signUpConfirmed(t, service, fake)
+	fake.AddGroup(cognitofake.Group{Name: "support"})
+	ctx := context.Background()
+
+	if err := admin.AddUserToGroup(ctx, testUsername, "missing"); !errors.Is(err, ErrGroupNotFound) {
+		t.Fatalf("AddUserToGroup() missing group error = %v, want %v", err, ErrGroupNotFound)
+	}
+	if err := admin.AddUserToGroup(ctx, "nobody@example.com", "support"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("AddUserToGroup() missing user error = %v, want %v", err, ErrUserNotFound)
+	}
+
+	if err := admin.AddUserToGroup(ctx, testUsername, "support"); err != nil {
+		t.Fatalf("AddUserToGroup() error = %v", err)
+	}
+	if user, _ := fake.User(testUsername); len(user.Groups) != 1 || user.Groups[0] != "support" {
+		t.Fatalf("Groups = %v, want [support]", user.Groups)
+	}
+
+	if err := admin.RemoveUserFromGroup(ctx, testUsername, "support"); err != nil {
+		t.Fatalf("RemoveUserFromGroup() error = %v", err)
+	}
+	if user, _ := fake.User(testUsername); len(user.Groups) != 0 {
+		t.Fatalf("Groups = %v, want none", user.Groups)
+	}
+}
+
+func TestAdminSignOutAndDeleteUser(t *testing.T) {
+	admin, service, fake := newFakeAdminService(t)
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+	ctx := context.Background()
+
+	if err := admin.SignOutUser(ctx, testUsername); err != nil {
+		t.Fatalf("SignOutUser() error = %v", err)
+	}
+	if _, err := service.GetTokensFromRefreshToken(ctx, models.RefreshTokenInput{RefreshToken: tokens.RefreshToken}); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("GetTokensFromRefreshToken() after sign out error = %v, want %v", err, ErrNotAuthorized)
+	}
+
+	if err := admin.DeleteUser(ctx, testUsername); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if _, err := admin.GetUser(ctx, testUsername); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("GetUser() after delete error = %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestAdminListUsers(t *testing.T) {
+	admin, _, fake := newFakeAdminService(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		fake.AddUser(cognitofake.User{
+			Username:   name + "@example.com",
+			Attributes: map[string]string{"email": name + "@example.com", "name": strings.ToUpper(name[:1]) + name[1:]},
+			Confirmed:  name != "erin",
+		})
+	}
+
+	// Walk every page
+	var usernames []string
+	input := models.ListUsersInput{Limit: 2}
+	for {
+		page, err := admin.ListUsers(ctx, input)
+		if err != nil {
+			t.Fatalf("ListUsers() error = %v", err)
+		}
+		if len(page.Users) > 2 {
+			t.Fatalf("page has %d users, want at most 2", len(page.Users))
+		}
+		for _, user := range page.Users {
+			usernames = append(usernames, user.Username)
+		}
+		if page.NextToken == "" {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+	if len(usernames) != 5 {
+		t.Fatalf("listed %v, want 5 users", usernames)
+	}
+
+	page, err := admin.ListUsers(ctx, models.ListUsersInput{Email: "ca", Prefix: true})
+	if err != nil || len(page.Users) != 1 || page.Users[0].Name != "Carol" {
+		t.Fatalf("ListUsers() email prefix = %+v, %v, want carol", page, err)
+	}
+
+	page, err = admin.ListUsers(ctx, models.ListUsersInput{Status: "UNCONFIRMED"})
+	if err != nil || len(page.Users) != 1 || page.Users[0].Username != "erin@example.com" {
+		t.Fatalf("ListUsers() status = %+v, %v, want erin", page, err)
+	}
+
+	page, err = admin.ListUsers(ctx, models.ListUsersInput{Name: `Bob" or "x`})
+	if err != nil || len(page.Users) != 0 {
+		t.Fatalf("ListUsers() quoted name = %+v, %v, want no users", page, err)
+	}
+}
+
+func TestListUsersFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input models.ListUsersInput
+		want  string
+	}{
+		{"none", models.ListUsersInput{}, ""},
+		{"email", models.ListUsersInput{Email: "jane@example.com"}, `email = "jane@example.com"`},
+		{"prefix", models.ListUsersInput{Name: "Ja", Prefix: true}, `name ^= "Ja"`},
+		{"status", models.ListUsersInput{Status: "CONFIRMED"}, `cognito:user_status = "CONFIRMED"`},
+		{"escaped", models.ListUsersInput{Username: `a"b\c`}, `username = "a\"b\\c"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listUsersFilter(tt.input)
+			if err != nil || got != tt.want {
+				t.Fatalf("listUsersFilter() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	invalid := []models.ListUsersInput{
+		{Email: "jane@example.com", Name: "Jane"},
+		{Status: "CONFIRMED", Prefix: true},
+	}
+	for _, input := range invalid {
+		if _, err := listUsersFilter(input); !errors.Is(err, ErrInvalidParameter) {
+			t.Fatalf("listUsersFilter(%+v) error = %v, want %v", input, err, ErrInvalidParameter)
+		}
+	}
+}
+
+func TestAdminListUsersInvalidToken(t *testing.T) {
+	admin, _, _ := newFakeAdminService(t)
+
+	if _, err := admin.ListUsers(context.Background(), models.ListUsersInput{NextToken: "not base64!"}); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("ListUsers() error = %v, want %v", err, ErrInvalidParameter)
+	}
+}
+
+func TestAdminManageGroup(t *testing.T) {
+	admin, _, _ := newFakeAdminService(t)
+	ctx := context.Background()
+	roleArn := "arn:aws:iam::123456789012:role/support"
+
+	group, err := admin.CreateGroup(ctx, models.CreateGroupInput{Name: "support", Description: "Support staff", Precedence: aws.Int32(10), RoleArn: roleArn})
+	if err != nil {
+		t.Fatalf("CreateGroup() error = %v", err)
+	}
+	if group.Name != "support" || group.Description != "Support staff" || aws.ToInt32(group.Precedence) != 10 || group.RoleArn != roleArn || group.CreatedAt.IsZero() {
+		t.Fatalf("unexpected group: %+v", group)
+	}
+
+	if _, err := admin.CreateGroup(ctx, models.CreateGroupInput{Name: "support"}); !errors.Is(err, ErrGroupExists) {
+		t.Fatalf("CreateGroup() duplicate error = %v, want %v", err, ErrGroupExists)
+	}
+	if _, err := admin.CreateGroup(ctx, models.CreateGroupInput{Name: "ops", RoleArn: "support"}); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("CreateGroup() invalid role error = %v, want %v", err, ErrInvalidParameter)
+	}
+
+	// Only the precedence changes
+	group, err = admin.UpdateGroup(ctx, "support", models.UpdateGroupInput{Precedence: aws.Int32(1)})
+	if err != nil || aws.ToInt32(group.Precedence) != 1 || group.Description != "Support staff" || group.RoleArn != roleArn {
+		t.Fatalf("UpdateGroup() = %+v, %v", group, err)
+	}
+
+	if err := admin.DeleteGroup(ctx, "support"); err != nil {
+		t.Fatalf("DeleteGroup() error = %v", err)
+	}
+	if _, err := admin.GetGroup(ctx, "support"); !errors.Is(err, ErrGroupNotFound) {
+		t.Fatalf("GetGroup() after delete error = %v, want %v", err, ErrGroupNotFound)
+	}
+	if _, err := admin.UpdateGroup(ctx, "support", models.UpdateGroupInput{}); !errors.Is(err, ErrGroupNotFound) {
+		t.Fatalf("UpdateGroup() after delete error = %v, want %v", err, ErrGroupNotFound)
+	}
+}
+
+func TestAdminListGroups(t *testing.T) {
+	admin, service, fake := newFakeAdminService(t)
+	signUpConfirmed(t, service, fake)
+	ctx := context.Background()
+
+	for _, name := range []string{"admin", "ops", "support"} {
+		fake.AddGroup(cognitofake.Group{Name: name})
+	}
+	for _, name := range []string{"ops", "support"} {
+		if err := admin.AddUserToGroup(ctx, testUsername, name); err != nil {
+			t.Fatalf("AddUserToGroup() error = %v", err)
+		}
+	}
+
+	// Walk every page
+	var names []string
+	input := models.PageInput{Limit: 2}
+	for {
+		page, err := admin.ListGroups(ctx, input)
+		if err != nil {
+			t.Fatalf("ListGroups() error = %v", err)
+		}
+		for _, group := range page.Groups {
+			names = append(names, group.Name)
+		}
+		if page.NextToken == "" {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+	if strings.Join(names, ",") != "admin,ops,support" {
+		t.Fatalf("listed %v, want admin, ops and support", names)
+	}
+
+	groups, err := admin.ListGroupsForUser(ctx, testUsername, models.PageInput{})
+	if err != nil || len(groups.Groups) != 2 || groups.Groups[0].Name != "ops" {
+		t.Fatalf("ListGroupsForUser() = %+v, %v, want ops and support", groups, err)
+	}
+	if _, err := admin.ListGroupsForUser(ctx, "nobody@example.com", models.PageInput{}); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("ListGroupsForUser() missing user error = %v, want %v", err, ErrUserNotFound)
+	}
+
+	members, err := admin.ListUsersInGroup(ctx, "ops", models.PageInput{})
+	if err != nil || len(members.Users) != 1 || members.Users[0].Username != testUsername {
+		t.Fatalf("ListUsersInGroup() = %+v, %v, want %s", members, err, testUsername)
+	}
+	if _, err := admin.ListUsersInGroup(ctx, "missing", models.PageInput{}); !errors.Is(err, ErrGroupNotFound) {
+		t.Fatalf("ListUsersInGroup() missing group error = %v, want %v", err, ErrGroupNotFound)
+	}
+
+	// Deleting a group removes it from its members
+	if err := admin.DeleteGroup(ctx, "ops"); err != nil {
+		t.Fatalf("DeleteGroup() error = %v", err)
+	}
+	if user, _ := fake.User(testUsername); len(user.Groups) != 1 || user.Groups[0] != "support" {
+		t.Fatalf("Groups = %v, want [support]", user.Groups)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"example.com/go-cognito/models"
@@ -13,21 +14,37 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // Define struct fields
 type AuthService struct {
-	CognitoClient *cognitoidentityprovider.Client
+	CognitoClient CognitoAPI
 	ClientID      string
 	ClientSecret  string
 	Region        string
 	UserPoolID    string
+	JWKSURL       string
+	// Expected iss claim, derived from the region and user pool
+	Issuer string
+	// App clients whose tokens are accepted, defaults to ClientID
+	AllowedClientIDs []string
+	// Leeway applied to exp, nbf and iat checks
+	ClockSkew time.Duration
+	// Issuer shown by authenticator apps for enrolled software tokens
+	TOTPIssuer string
+	// Flow used by SignIn, USER_PASSWORD_AUTH or USER_SRP_AUTH
+	AuthFlow types.AuthFlowType
+	// Attributes users may change on their own profile, custom attributes need a custom: prefix
+	EditableAttributes []string
+
+	jwksMu     sync.Mutex
+	jwks       keyfunc.Keyfunc
+	jwksCancel context.CancelFunc
 }
 
 // Define constructor
-func NewAuthService(client *cognitoidentityprovider.Client, clientId, clientSecret, region, userPoolId string) *AuthService {
-	if client == nil {
+func NewAuthService(client CognitoAPI, clientId, clientSecret, region, userPoolId string) *AuthService {
+	if isNilClient(client) {
 		log.Fatalf("Cognito Client cannot be nil.")
 	}
 
@@ -41,36 +58,48 @@ func NewAuthService(client *cognitoidentityprovider.Client, clientId, clientSecr
 		ClientSecret:  clientSecret,
 		Region:        region,
 		UserPoolID:    userPoolId,
+		JWKSURL:       cognitoJWKSURL(region, userPoolId),
+		Issuer:        CognitoIssuer(region, userPoolId),
+
+		AllowedClientIDs: []string{clientId},
+		TOTPIssuer:       defaultTOTPIssuer,
+		AuthFlow:         types.AuthFlowTypeUserPasswordAuth,
+
+		EditableAttributes: defaultEditableAttributes,
 	}
 }
 
 // Implement SignUp business logic
 func (s *AuthService) SignUp(context context.Context, user models.SignUpInput) error {
+	attributes := []types.AttributeType{
+		{Name: aws.String("name"), Value: aws.String(user.Name)},
+		{Name: aws.String("email"), Value: aws.String(user.UserName)},
+	}
+	if user.PhoneNumber != "" {
+		attributes = append(attributes, types.AttributeType{Name: aws.String("phone_number"), Value: aws.String(user.PhoneNumber)})
+	}
+
 	// Use SignUp API to register the user
 	_, err := s.CognitoClient.SignUp(context, &cognitoidentityprovider.SignUpInput{
-		ClientId: aws.String(s.ClientID),
-		Username: aws.String(user.UserName),
-		Password: aws.String(user.Password),
-		UserAttributes: []types.AttributeType{
-			{Name: aws.String("name"), Value: aws.String(user.Name)},
-			{Name: aws.String("email"), Value: aws.String(user.UserName)},
-		},
-		SecretHash: aws.String(utils.GetSecretHash(s.ClientID, s.ClientSecret, user.UserName)),
+		ClientId:       aws.String(s.ClientID),
+		Username:       aws.String(user.UserName),
+		Password:       aws.String(user.Password),
+		UserAttributes: attributes,
+		SecretHash:     aws.String(utils.GetSecretHash(s.ClientID, s.ClientSecret, user.UserName)),
 	})
 
 	if err != nil {
-		var invalidPassword *types.InvalidPasswordException
-		if errors.As(err, &invalidPassword) {
-			return errors.New(*invalidPassword.Message)
-		}
-		return fmt.Errorf("Could not create new user: %w", err)
+		return cognitoError("Could not create new user", err)
 	}
 
 	return nil
 }
 
 func (s *AuthService) SignIn(context context.Context, user models.SignInInput) (models.AuthResponse, error) {
-	// var authResult *types.AuthenticationResultType
+	// With SRP the password never leaves the server
+	if s.AuthFlow == types.AuthFlowTypeUserSrpAuth {
+		return s.signInWithSRP(context, user)
+	}
 
 	output, err := s.CognitoClient.InitiateAuth(context, &cognitoidentityprovider.InitiateAuthInput{
 		AuthFlow: "USER_PASSWORD_AUTH",
@@ -83,94 +112,90 @@ func (s *AuthService) SignIn(context context.Context, user models.SignInInput) (
 	})
 
 	if err != nil {
-		var resetRequired *types.PasswordResetRequiredException
-		if errors.As(err, &resetRequired) {
-			log.Println(*resetRequired.Message)
-			return models.AuthResponse{}, errors.New(*resetRequired.Message)
-		}
-		return models.AuthResponse{}, fmt.Errorf("Could not sign in user %s: %w", user.UserName, err)
+		return models.AuthResponse{}, cognitoError(fmt.Sprintf("Could not sign in user %s", user.UserName), err)
 	}
 
-	if output.AuthenticationResult == nil || output.AuthenticationResult.IdToken == nil {
-		return models.AuthResponse{}, errors.New("Authentication result or ID Token is nil.")
-	}
-
-	authResult := output.AuthenticationResult
-	response := models.NewAuthResponse(authResult.AccessToken, authResult.IdToken, authResult.RefreshToken, authResult.TokenType, authResult.ExpiresIn)
-
-	return response, err
+	return authResponse(output.AuthenticationResult, output.ChallengeName, output.Session, output.ChallengeParameters)
 }
 
-func (s *AuthService) ConfirmAccount(context context.Context, user models.UserConfirmationInput) error {
-	_, err := s.CognitoClient.ConfirmSignUp(context, &cognitoidentityprovider.ConfirmSignUpInput{
-		Username:         aws.String(user.Email),
-		ConfirmationCode: aws.String(user.Code),
-		ClientId:         aws.String(s.ClientID),
-		SecretHash:       aws.String(utils.GetSecretHash(s.ClientID, s.ClientSecret, user.Email)),
-	})
-
-	if err != nil {
-		log.Printf("ConfirmSignUp failed for user %s: %v", user.Email, err)
-		return fmt.Errorf("Account confirmation failed: %w", err)
+// Answers a challenge returned by SignIn, which may lead to tokens or to a further challenge
+func (s *AuthService) RespondToChallenge(context context.Context, input models.RespondToChallengeInput) (models.AuthResponse, error) {
+	responses := map[string]string{
+		"USERNAME":    input.UserName,
+		"SECRET_HASH": utils.GetSecretHash(s.ClientID, s.ClientSecret, input.UserName),
 	}
 
-	return nil
-}
-
-func (s *AuthService) VerifyToken(jwtToken string) (bool, error) {
-
-	region := s.Region
-	userPoolId := s.UserPoolID
+	challengeName := types.ChallengeNameType(input.ChallengeName)
 
-	if region == "" || userPoolId == "" {
-		return false, fmt.Errorf("Region or User Pool ID environment variables are not set.")
+	switch challengeName {
+	case types.ChallengeNameTypeSoftwareTokenMfa:
+		if input.Code == "" {
+			return models.AuthResponse{}, &AuthError{Kind: ErrInvalidParameter, Message: "code is required to answer SOFTWARE_TOKEN_MFA."}
+		}
+		responses["SOFTWARE_TOKEN_MFA_CODE"] = input.Code
+	case types.ChallengeNameTypeSmsMfa:
+		if input.Code == "" {
+			return models.AuthResponse{}, &AuthError{Kind: ErrInvalidParameter, Message: "code is required to answer SMS_MFA."}
+		}
+		responses["SMS_MFA_CODE"] = input.Code
+	case types.ChallengeNameTypeCustomChallenge:
+		if input.Code == "" {
+			return models.AuthResponse{}, &AuthError{Kind: ErrInvalidParameter, Message: "code is required to answer CUSTOM_CHALLENGE."}
+		}
+		responses["ANSWER"] = input.Code
+	case types.ChallengeNameTypeNewPasswordRequired:
+		if input.NewPassword == "" {
+			return models.AuthResponse{}, &AuthError{Kind: ErrInvalidParameter, Message: "newPassword is required to answer NEW_PASSWORD_REQUIRED."}
+		}
+		responses["NEW_PASSWORD"] = input.NewPassword
+		for name, value := range input.Attributes {
+			responses["userAttributes."+name] = value
+		}
+	default:
+		return models.AuthResponse{}, &AuthError{Kind: ErrInvalidParameter, Message: fmt.Sprintf("Unsupported challenge %s.", input.ChallengeName)}
 	}
 
-	jwksURL := fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s/.well-known/jwks.json", region, userPoolId)
-
-	// Create the keyfunc.Keyfunc.
-	jwks, err := keyfunc.NewDefault([]string{jwksURL})
-	if err != nil {
-		return false, fmt.Errorf("Failed to create JWKS from URL: %v", err)
-	}
+	output, err := s.CognitoClient.RespondToAuthChallenge(context, &cognitoidentityprovider.RespondToAuthChallengeInput{
+		ClientId:           aws.String(s.ClientID),
+		ChallengeName:      challengeName,
+		Session:            aws.String(input.Session),
+		ChallengeResponses: responses,
+	})
 
-	// Parse the JWT
-	token, err := jwt.Parse(jwtToken, jwks.Keyfunc)
 	if err != nil {
-		return false, fmt.Errorf("Failed to parse JWT: %v", err)
-	}
-
-	if !token.Valid {
-		return false, fmt.Errorf("The token is not valid.")
+		return models.AuthResponse{}, cognitoError(fmt.Sprintf("Could not answer %s challenge", input.ChallengeName), err)
 	}
 
-	log.Println("The token is valid.")
-
-	claims, ok := token.Claims.(jwt.MapClaims)
+	return authResponse(output.AuthenticationResult, output.ChallengeName, output.Session, output.ChallengeParameters)
+}
 
-	if !ok {
-		return false, fmt.Errorf("Failed to parse claims.")
+// Builds the sign in response from either the issued tokens or the next challenge
+func authResponse(authResult *types.AuthenticationResultType, challengeName types.ChallengeNameType, session *string, challengeParameters map[string]string) (models.AuthResponse, error) {
+	if challengeName != "" {
+		return models.NewChallengeResponse(string(challengeName), session, challengeParameters), nil
 	}
 
-	tokenUse, ok := claims["token_use"].(string)
-
-	if !ok || tokenUse != "access" {
-		return false, fmt.Errorf("Invalid token use - must be an access token.")
+	if authResult == nil || authResult.IdToken == nil {
+		return models.AuthResponse{}, errors.New("Authentication result or ID Token is nil.")
 	}
 
-	log.Printf("Token Claims: %+v\n", claims)
-
-	exp, ok := claims["exp"].(float64)
+	return models.NewAuthResponse(authResult.AccessToken, authResult.IdToken, authResult.RefreshToken, authResult.TokenType, authResult.ExpiresIn), nil
+}
 
-	if !ok {
-		return false, fmt.Errorf("Failed to get exp claim.")
-	}
+func (s *AuthService) ConfirmAccount(context context.Context, user models.UserConfirmationInput) error {
+	_, err := s.CognitoClient.ConfirmSignUp(context, &cognitoidentityprovider.ConfirmSignUpInput{
+		Username:         aws.String(user.Email),
+		ConfirmationCode: aws.String(user.Code),
+		ClientId:         aws.String(s.ClientID),
+		SecretHash:       aws.String(utils.GetSecretHash(s.ClientID, s.ClientSecret, user.Email)),
+	})
 
-	if time.Now().Unix() > int64(exp) {
-		return false, fmt.Errorf("Token has expired.")
+	if err != nil {
+		log.Printf("ConfirmSignUp failed for user %s: %v", user.Email, err)
+		return cognitoError("Account confirmation failed", err)
 	}
 
-	return true, nil
+	return nil
 }
 
 func (s *AuthService) ForgotPassword(context context.Context, user models.ForgotPasswordInput) (*types.CodeDeliveryDetailsType, error) {
@@ -182,7 +207,7 @@ func (s *AuthService) ForgotPassword(context context.Context, user models.Forgot
 
 	if err != nil {
 		log.Printf("Couldn't start password reset for user '%v'. Here;s why: %v\n", user.UserName, err)
-		return nil, fmt.Errorf("Password reset failed: %w", err)
+		return nil, cognitoError("Password reset failed", err)
 	}
 
 	log.Println(output.CodeDeliveryDetails)
@@ -198,13 +223,8 @@ func (s *AuthService) ConfirmForgotPassword(context context.Context, user models
 		SecretHash:       aws.String(utils.GetSecretHash(s.ClientID, s.ClientSecret, user.UserName)),
 	})
 	if err != nil {
-		var invalidPassword *types.InvalidPasswordException
-		if errors.As(err, &invalidPassword) {
-			log.Println(*invalidPassword.Message)
-		} else {
-			log.Printf("Couldn't confirm user %v. Here's why: %v", user.UserName, err)
-			return fmt.Errorf("Password reset failed: %w", err)
-		}
+		log.Printf("Couldn't confirm user %v. Here's why: %v", user.UserName, err)
+		return cognitoError("Password reset failed", err)
 	}
 	return nil
 }
@@ -218,7 +238,7 @@ func (s *AuthService) ResendConfirmationCode(context context.Context, user model
 
 	if err != nil {
 		log.Printf("Couldn't resend confirmation code to user '%v'. Here;s why: %v\n", user.UserName, err)
-		return nil, fmt.Errorf("Password reset failed: %w", err)
+		return nil, cognitoError("Could not resend confirmation code", err)
 	}
 
 	return output.CodeDeliveryDetails, nil
@@ -232,7 +252,7 @@ func (s *AuthService) GetTokensFromRefreshToken(context context.Context, user mo
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("Could not obtain new token: %w", err)
+		return nil, cognitoError("Could not obtain new token", err)
 	}
 
 	return output.AuthenticationResult, nil
@@ -244,7 +264,7 @@ func (s *AuthService) SignOut(context context.Context, user models.SignOutInput)
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("Could not sign out: %w", err)
+		return nil, cognitoError("Could not sign out", err)
 	}
 
 	return output, nil
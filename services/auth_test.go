@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Answers every Cognito API call with a canned JSON response
+type stubHTTPClient struct {
+	status  int
+	body    string
+	targets []string
+}
+
+func (c *stubHTTPClient) Do(request *http.Request) (*http.Response, error) {
+	c.targets = append(c.targets, request.Header.Get("X-Amz-Target"))
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/x-amz-json-1.1")
+
+	return &http.Response{
+		StatusCode: c.status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(c.body)),
+		Request:    request,
+	}, nil
+}
+
+func newStubbedAuthService(httpClient *stubHTTPClient) *AuthService {
+	client := cognitoidentityprovider.New(cognitoidentityprovider.Options{
+		Region:      "us-east-1",
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient:  httpClient,
+		Retryer:     aws.NopRetryer{},
+	})
+	return NewAuthService(client, "client-id", "client-secret", "us-east-1", "us-east-1_pool")
+}
+
+func TestConfirmForgotPasswordReturnsPasswordPolicyError(t *testing.T) {
+	httpClient := &stubHTTPClient{
+		status: http.StatusBadRequest,
+		body:   `{"__type":"InvalidPasswordException","message":"Password did not conform with policy: Password must have uppercase characters"}`,
+	}
+	service := newStubbedAuthService(httpClient)
+
+	err := service.ConfirmForgotPassword(context.Background(), models.ConfirmForgotPasswordInput{
+		UserName:         "jane@example.com",
+		Password:         "lowercase-only-1",
+		ConfirmationCode: "123456",
+	})
+
+	if !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("ConfirmForgotPassword() error = %v, want %v", err, ErrInvalidPassword)
+	}
+
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("ConfirmForgotPassword() error = %T, want *AuthError", err)
+	}
+	if want := []string{"Password must have uppercase characters"}; !reflect.DeepEqual(authErr.Details, want) {
+		t.Fatalf("Details = %q, want %q", authErr.Details, want)
+	}
+
+	if len(httpClient.targets) != 1 || !strings.HasSuffix(httpClient.targets[0], ".ConfirmForgotPassword") {
+		t.Fatalf("Cognito calls = %v, want a single ConfirmForgotPassword", httpClient.targets)
+	}
+}
+
+func TestConfirmForgotPasswordSucceeds(t *testing.T) {
+	service := newStubbedAuthService(&stubHTTPClient{status: http.StatusOK, body: `{}`})
+
+	err := service.ConfirmForgotPassword(context.Background(), models.ConfirmForgotPasswordInput{
+		UserName:         "jane@example.com",
+		Password:         "Valid-Password-1",
+		ConfirmationCode: "123456",
+	})
+	if err != nil {
+		t.Fatalf("ConfirmForgotPassword() error = %v", err)
+	}
+}
+
+func TestPasswordPolicyViolations(t *testing.T) {
+	tests := []struct {
+		message string
+		want    []string
+	}{
+		{"Password did not conform with policy: Password not long enough", []string{"Password not long enough"}},
+		{"Password does not conform to policy: Password must have numeric characters; Password must have symbol characters", []string{"Password must have numeric characters", "Password must have symbol characters"}},
+		{"Password not long enough", []string{"Password not long enough"}},
+	}
+
+	for _, tt := range tests {
+		if got := passwordPolicyViolations(tt.message); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("passwordPolicyViolations(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+var _ CognitoAPI = (*cognitofake.Client)(nil)
+
+const (
+	testUsername = "jane@example.com"
+	testPassword = "Password-123"
+)
+
+func newFakeAuthService(t *testing.T) (*AuthService, *cognitofake.Client) {
+	t.Helper()
+
+	fake := cognitofake.New("client-id", "client-secret")
+	service := NewAuthService(fake, "client-id", "client-secret", "us-east-1", "us-east-1_pool")
+	t.Cleanup(service.Close)
+
+	return service, fake
+}
+
+// Registers and confirms a user through the service
+func signUpConfirmed(t *testing.T, service *AuthService, fake *cognitofake.Client) {
+	t.Helper()
+
+	ctx := context.Background()
+	if err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	code, _ := fake.Code(testUsername, cognitofake.CodeSignUp)
+	if err := service.ConfirmAccount(ctx, models.UserConfirmationInput{Email: testUsername, Code: code}); err != nil {
+		t.Fatalf("ConfirmAccount() error = %v", err)
+	}
+}
+
+func signIn(t *testing.T, service *AuthService, password string) models.AuthResponse {
+	t.Helper()
+
+	response, err := service.SignIn(context.Background(), models.SignInInput{UserName: testUsername, Password: password})
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	return response
+}
+
+func TestSignUp(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+
+	if err := service.SignUp(context.Background(), models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	user, ok := fake.User(testUsername)
+	if !ok {
+		t.Fatal("user was not created")
+	}
+	if user.Confirmed || user.Attributes["email"] != testUsername || user.Attributes["name"] != "Jane" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+	if _, ok := fake.Code(testUsername, cognitofake.CodeSignUp); !ok {
+		t.Fatal("no confirmation code was sent")
+	}
+}
+
+func TestSignUpErrors(t *testing.T) {
+	service, _ := newFakeAuthService(t)
+	ctx := context.Background()
+
+	if err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane"})
+	if !errors.Is(err, ErrUsernameExists) {
+		t.Fatalf("SignUp() existing user error = %v, want %v", err, ErrUsernameExists)
+	}
+
+	err = service.SignUp(ctx, models.SignUpInput{UserName: "john@example.com", Password: "password-123", Name: "John"})
+	var authErr *AuthError
+	if !errors.Is(err, ErrInvalidPassword) || !errors.As(err, &authErr) || len(authErr.Details) != 1 {
+		t.Fatalf("SignUp() weak password error = %#v, want %v with details", err, ErrInvalidPassword)
+	}
+}
+
+func TestConfirmAccount(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	ctx := context.Background()
+
+	if err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	err := service.ConfirmAccount(ctx, models.UserConfirmationInput{Email: testUsername, Code: "000000x"})
+	if !errors.Is(err, ErrCodeMismatch) {
+		t.Fatalf("ConfirmAccount() wrong code error = %v, want %v", err, ErrCodeMismatch)
+	}
+
+	err = service.ConfirmAccount(ctx, models.UserConfirmationInput{Email: "john@example.com", Code: "123456"})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("ConfirmAccount() unknown user error = %v, want %v", err, ErrUserNotFound)
+	}
+
+	code, _ := fake.Code(testUsername, cognitofake.CodeSignUp)
+	if err := service.ConfirmAccount(ctx, models.UserConfirmationInput{Email: testUsername, Code: code}); err != nil {
+		t.Fatalf("ConfirmAccount() error = %v", err)
+	}
+	if user, _ := fake.User(testUsername); !user.Confirmed {
+		t.Fatal("user was not confirmed")
+	}
+}
+
+func TestSignIn(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+
+	response := signIn(t, service, testPassword)
+	if response.AccessToken == "" || response.IdToken == "" || response.RefreshToken == "" || response.TokenType != "Bearer" || response.ExpiresIn != 3600 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestSignInErrors(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	ctx := context.Background()
+
+	if err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	_, err := service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: testPassword})
+	if !errors.Is(err, ErrUserNotConfirmed) {
+		t.Fatalf("SignIn() unconfirmed error = %v, want %v", err, ErrUserNotConfirmed)
+	}
+
+	_, err = service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: "Wrong-Password-1"})
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("SignIn() wrong password error = %v, want %v", err, ErrNotAuthorized)
+	}
+
+	fake.FailNext("InitiateAuth", &types.PasswordResetRequiredException{Message: aws.String("Password reset required for the user")})
+	_, err = service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: testPassword})
+	if !errors.Is(err, ErrPasswordResetRequired) {
+		t.Fatalf("SignIn() reset required error = %v, want %v", err, ErrPasswordResetRequired)
+	}
+}
+
+func TestSignInNewPasswordRequired(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	fake.AddUser(cognitofake.User{Username: testUsername, Password: "Temporary-1", Confirmed: true, ForceChangePassword: true})
+	ctx := context.Background()
+
+	challenge, err := service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: "Temporary-1"})
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if challenge.ChallengeName != string(types.ChallengeNameTypeNewPasswordRequired) || challenge.Session == "" || challenge.AccessToken != "" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+
+	_, err = service.RespondToChallenge(ctx, models.RespondToChallengeInput{UserName: testUsername, ChallengeName: challenge.ChallengeName, Session: challenge.Session})
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("RespondToChallenge() without password error = %v, want %v", err, ErrInvalidParameter)
+	}
+
+	_, err = service.RespondToChallenge(ctx, models.RespondToChallengeInput{UserName: testUsername, ChallengeName: challenge.ChallengeName, Session: challenge.Session, NewPassword: "short"})
+	if !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("RespondToChallenge() weak password error = %v, want %v", err, ErrInvalidPassword)
+	}
+
+	challenge, _ = service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: "Temporary-1"})
+	response, err := service.RespondToChallenge(ctx, models.RespondToChallengeInput{
+		UserName:      testUsername,
+		ChallengeName: challenge.ChallengeName,
+		Session:       challenge.Session,
+		NewPassword:   testPassword,
+		Attributes:    map[string]string{"name": "Jane"},
+	})
+	if err != nil {
+		t.Fatalf("RespondToChallenge() error = %v", err)
+	}
+	if response.AccessToken == "" || response.ChallengeName != "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	user, _ := fake.User(testUsername)
+	if user.ForceChangePassword || user.Password != testPassword || user.Attributes["name"] != "Jane" {
+		t.Fatalf("user was not updated: %+v", user)
+	}
+
+	_, err = service.RespondToChallenge(ctx, models.RespondToChallengeInput{UserName: testUsername, ChallengeName: challenge.ChallengeName, Session: challenge.Session, NewPassword: testPassword})
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("RespondToChallenge() reused session error = %v, want %v", err, ErrNotAuthorized)
+	}
+}
+
+func TestRespondToChallengeUnsupported(t *testing.T) {
+	service, _ := newFakeAuthService(t)
+
+	_, err := service.RespondToChallenge(context.Background(), models.RespondToChallengeInput{UserName: testUsername, ChallengeName: "DEVICE_SRP_AUTH", Session: "session"})
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("RespondToChallenge() error = %v, want %v", err, ErrInvalidParameter)
+	}
+}
+
+func TestForgotPassword(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	ctx := context.Background()
+
+	details, err := service.ForgotPassword(ctx, models.ForgotPasswordInput{UserName: testUsername})
+	if err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	if aws.ToString(details.Destination) != "j***@e***" || details.DeliveryMedium != types.DeliveryMediumTypeEmail {
+		t.Fatalf("unexpected delivery details: %+v", details)
+	}
+
+	_, err = service.ForgotPassword(ctx, models.ForgotPasswordInput{UserName: "john@example.com"})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("ForgotPassword() unknown user error = %v, want %v", err, ErrUserNotFound)
+	}
+
+	fake.FailNext("ForgotPassword", &types.LimitExceededException{Message: aws.String("Attempt limit exceeded, please try after some time.")})
+	_, err = service.ForgotPassword(ctx, models.ForgotPasswordInput{UserName: testUsername})
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("ForgotPassword() throttled error = %v, want %v", err, ErrLimitExceeded)
+	}
+}
+
+func TestConfirmForgotPassword(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	ctx := context.Background()
+
+	err := service.ConfirmForgotPassword(ctx, models.ConfirmForgotPasswordInput{UserName: testUsername, Password: "New-Password-1", ConfirmationCode: "123456"})
+	if !errors.Is(err, ErrExpiredCode) {
+		t.Fatalf("ConfirmForgotPassword() without code error = %v, want %v", err, ErrExpiredCode)
+	}
+
+	if _, err := service.ForgotPassword(ctx, models.ForgotPasswordInput{UserName: testUsername}); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	code, _ := fake.Code(testUsername, cognitofake.CodeForgotPassword)
+
+	err = service.ConfirmForgotPassword(ctx, models.ConfirmForgotPasswordInput{UserName: testUsername, Password: "new-password-1", ConfirmationCode: code})
+	if !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("ConfirmForgotPassword() weak password error = %v, want %v", err, ErrInvalidPassword)
+	}
+
+	err = service.ConfirmForgotPassword(ctx, models.ConfirmForgotPasswordInput{UserName: testUsername, Password: "New-Password-1", ConfirmationCode: code + "0"})
+	if !errors.Is(err, ErrCodeMismatch) {
+		t.Fatalf("ConfirmForgotPassword() wrong code error = %v, want %v", err, ErrCodeMismatch)
+	}
+
+	err = service.ConfirmForgotPassword(ctx, models.ConfirmForgotPasswordInput{UserName: testUsername, Password: "New-Password-1", ConfirmationCode: code})
+	if err != nil {
+		t.Fatalf("ConfirmForgotPassword() error = %v", err)
+	}
+
+	signIn(t, service, "New-Password-1")
+}
+
+func TestResendConfirmationCode(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	ctx := context.Background()
+
+	if err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	details, err := service.ResendConfirmationCode(ctx, models.ForgotPasswordInput{UserName: testUsername})
+	if err != nil || details == nil {
+		t.Fatalf("ResendConfirmationCode() = %v, %v", details, err)
+	}
+
+	code, _ := fake.Code(testUsername, cognitofake.CodeSignUp)
+	if err := service.ConfirmAccount(ctx, models.UserConfirmationInput{Email: testUsername, Code: code}); err != nil {
+		t.Fatalf("ConfirmAccount() with resent code error = %v", err)
+	}
+
+	_, err = service.ResendConfirmationCode(ctx, models.ForgotPasswordInput{UserName: testUsername})
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("ResendConfirmationCode() confirmed user error = %v, want %v", err, ErrInvalidParameter)
+	}
+}
+
+func TestGetTokensFromRefreshToken(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	ctx := context.Background()
+
+	response := signIn(t, service, testPassword)
+
+	result, err := service.GetTokensFromRefreshToken(ctx, models.RefreshTokenInput{RefreshToken: response.RefreshToken})
+	if err != nil {
+		t.Fatalf("GetTokensFromRefreshToken() error = %v", err)
+	}
+	if aws.ToString(result.AccessToken) == "" || aws.ToString(result.AccessToken) == response.AccessToken {
+		t.Fatalf("expected a new access token, got %+v", result)
+	}
+
+	_, err = service.GetTokensFromRefreshToken(ctx, models.RefreshTokenInput{RefreshToken: "invalid"})
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("GetTokensFromRefreshToken() invalid token error = %v, want %v", err, ErrNotAuthorized)
+	}
+}
+
+func TestSignOut(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	ctx := context.Background()
+
+	response := signIn(t, service, testPassword)
+
+	if _, err := service.SignOut(ctx, models.SignOutInput{AccessToken: response.AccessToken}); err != nil {
+		t.Fatalf("SignOut() error = %v", err)
+	}
+
+	_, err := service.GetTokensFromRefreshToken(ctx, models.RefreshTokenInput{RefreshToken: response.RefreshToken})
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("refresh after SignOut() error = %v, want %v", err, ErrNotAuthorized)
+	}
+
+	_, err = service.SignOut(ctx, models.SignOutInput{AccessToken: response.AccessToken})
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("second SignOut() error = %v, want %v", err, ErrNotAuthorized)
+	}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+
+	"example.com/go-cognito/cognitofake"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+)
+
+func TestIsNilClient(t *testing.T) {
+	var sdkClient *cognitoidentityprovider.Client
+	var fakeClient *cognitofake.Client
+
+	tests := []struct {
+		name   string
+		client CognitoAdminAPI
+		want   bool
+	}{
+		{"nil interface", nil, true},
+		{"nil SDK client", sdkClient, true},
+		{"nil fake client", fakeClient, true},
+		{"fake client", cognitofake.New("client-id", "client-secret"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNilClient(tt.client); got != tt.want {
+				t.Fatalf("isNilClient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Errors returned by AuthService when Cognito rejects a request.
+// Use errors.Is to tell them apart, the returned error carries Cognito's message.
+var (
+	ErrUserNotFound          = errors.New("User not found.")
+	ErrUsernameExists        = errors.New("User already exists.")
+	ErrCodeMismatch          = errors.New("Invalid verification code.")
+	ErrExpiredCode           = errors.New("Verification code has expired.")
+	ErrNotAuthorized         = errors.New("Not authorized.")
+	ErrUserNotConfirmed      = errors.New("User is not confirmed.")
+	ErrPasswordResetRequired = errors.New("Password reset required.")
+	ErrLimitExceeded         = errors.New("Attempt limit exceeded, please try again later.")
+	ErrTooManyRequests       = errors.New("Too many requests, please try again later.")
+	ErrInvalidPassword       = errors.New("Password does not conform to policy.")
+	ErrInvalidParameter      = errors.New("Invalid parameter.")
+	ErrGroupNotFound         = errors.New("Group not found.")
+	ErrGroupExists           = errors.New("Group already exists.")
+)
+
+// Error returned for a Cognito exception with a known meaning
+type AuthError struct {
+	// One of the Err* sentinels above
+	Kind error
+	// Message reported by Cognito
+	Message string
+	// Individual violations, e.g. each password policy rule that was not met
+	Details []string
+	// Underlying Cognito exception
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Kind.Error()
+}
+
+func (e *AuthError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Translates a Cognito exception into an AuthError, or wraps it with context if it has no known meaning
+func cognitoError(context string, err error) error {
+	if kind, message, ok := classifyCognitoError(err); ok {
+		authErr := &AuthError{Kind: kind, Message: message, Err: err}
+		if kind == ErrInvalidPassword {
+			authErr.Details = passwordPolicyViolations(message)
+		}
+		return authErr
+	}
+	return fmt.Errorf("%s: %w", context, err)
+}
+
+func classifyCognitoError(err error) (error, string, bool) {
+	var (
+		userNotFound          *types.UserNotFoundException
+		usernameExists        *types.UsernameExistsException
+		aliasExists           *types.AliasExistsException
+		codeMismatch          *types.CodeMismatchException
+		expiredCode           *types.ExpiredCodeException
+		notAuthorized         *types.NotAuthorizedException
+		userNotConfirmed      *types.UserNotConfirmedException
+		passwordResetRequired *types.PasswordResetRequiredException
+		limitExceeded         *types.LimitExceededException
+		tooManyRequests       *types.TooManyRequestsException
+		tooManyFailedAttempts *types.TooManyFailedAttemptsException
+		invalidPassword       *types.InvalidPasswordException
+		invalidParameter      *types.InvalidParameterException
+		enableSoftwareToken   *types.EnableSoftwareTokenMFAException
+		softwareTokenNotFound *types.SoftwareTokenMFANotFoundException
+		groupExists           *types.GroupExistsException
+	)
+
+	switch {
+	case errors.As(err, &userNotFound):
+		return ErrUserNotFound, aws.ToString(userNotFound.Message), true
+	case errors.As(err, &usernameExists):
+		return ErrUsernameExists, aws.ToString(usernameExists.Message), true
+	case errors.As(err, &aliasExists):
+		return ErrUsernameExists, aws.ToString(aliasExists.Message), true
+	case errors.As(err, &codeMismatch):
+		return ErrCodeMismatch, aws.ToString(codeMismatch.Message), true
+	case errors.As(err, &expiredCode):
+		return ErrExpiredCode, aws.ToString(expiredCode.Message), true
+	case errors.As(err, &notAuthorized):
+		return ErrNotAuthorized, aws.ToString(notAuthorized.Message), true
+	case errors.As(err, &userNotConfirmed):
+		return ErrUserNotConfirmed, aws.ToString(userNotConfirmed.Message), true
+	case errors.As(err, &passwordResetRequired):
+		return ErrPasswordResetRequired, aws.ToString(passwordResetRequired.Message), true
+	case errors.As(err, &limitExceeded):
+		return ErrLimitExceeded, aws.ToString(limitExceeded.Message), true
+	case errors.As(err, &tooManyRequests):
+		return ErrTooManyRequests, aws.ToString(tooManyRequests.Message), true
+	case errors.As(err, &tooManyFailedAttempts):
+		return ErrTooManyRequests, aws.ToString(tooManyFailedAttempts.Message), true
+	case errors.As(err, &invalidPassword):
+		return ErrInvalidPassword, aws.ToString(invalidPassword.Message), true
+	case errors.As(err, &invalidParameter):
+		return ErrInvalidParameter, aws.ToString(invalidParameter.Message), true
+	case errors.As(err, &enableSoftwareToken):
+		return ErrCodeMismatch, aws.ToString(enableSoftwareToken.Message), true
+	case errors.As(err, &softwareTokenNotFound):
+		return ErrInvalidParameter, aws.ToString(softwareTokenNotFound.Message), true
+	case errors.As(err, &groupExists):
+		return ErrGroupExists, aws.ToString(groupExists.Message), true
+	}
+
+	return nil, "", false
+}
+
+// Extracts the policy rules from messages such as
+// "Password did not conform with policy: Password must have uppercase characters"
+func passwordPolicyViolations(message string) []string {
+	_, violations, found := strings.Cut(message, "policy:")
+	if !found {
+		violations = message
+	}
+
+	var details []string
+	for _, violation := range strings.Split(violations, ";") {
+		if violation = strings.TrimSpace(violation); violation != "" {
+			details = append(details, violation)
+		}
+	}
+	return details
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+func TestCognitoError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"user not found", &types.UserNotFoundException{Message: aws.String("User does not exist.")}, ErrUserNotFound},
+		{"username exists", &types.UsernameExistsException{Message: aws.String("User already exists")}, ErrUsernameExists},
+		{"code mismatch", &types.CodeMismatchException{Message: aws.String("Invalid code provided")}, ErrCodeMismatch},
+		{"expired code", &types.ExpiredCodeException{Message: aws.String("Code expired")}, ErrExpiredCode},
+		{"not authorized", &types.NotAuthorizedException{Message: aws.String("Incorrect username or password.")}, ErrNotAuthorized},
+		{"not confirmed", &types.UserNotConfirmedException{Message: aws.String("User is not confirmed.")}, ErrUserNotConfirmed},
+		{"limit exceeded", &types.LimitExceededException{Message: aws.String("Attempt limit exceeded")}, ErrLimitExceeded},
+		{"too many requests", &types.TooManyRequestsException{Message: aws.String("Too many requests")}, ErrTooManyRequests},
+		{"too many failed attempts", &types.TooManyFailedAttemptsException{Message: aws.String("Too many failed attempts")}, ErrTooManyRequests},
+		{"invalid password", &types.InvalidPasswordException{Message: aws.String("Password not long enough")}, ErrInvalidPassword},
+		{"group exists", &types.GroupExistsException{Message: aws.String("A group with the name already exists.")}, ErrGroupExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cognitoError("Request failed", tt.err)
+
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("cognitoError() = %v, want %v", err, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("cognitoError() does not wrap the Cognito exception")
+			}
+
+			message := tt.err.(interface{ ErrorMessage() string }).ErrorMessage()
+
+			var authErr *AuthError
+			if !errors.As(err, &authErr) || authErr.Error() != message {
+				t.Fatalf("cognitoError() = %v, want %q", err, message)
+			}
+		})
+	}
+}
+
+func TestCognitoErrorUnknown(t *testing.T) {
+	cause := &types.ResourceNotFoundException{Message: aws.String("User pool client does not exist.")}
+	err := cognitoError("Request failed", cause)
+
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		t.Fatalf("cognitoError() = %v, want plain wrapped error", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Fatalf("cognitoError() does not wrap the Cognito exception")
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+func (s *AdminService) CreateGroup(context context.Context, input models.CreateGroupInput) (models.Group, error) {
+	createGroupInput := &cognitoidentityprovider.CreateGroupInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		GroupName:  aws.String(input.Name),
+		Precedence: input.Precedence,
+	}
+	if input.Description != "" {
+		createGroupInput.Description = aws.String(input.Description)
+	}
+	if input.RoleArn != "" {
+		createGroupInput.RoleArn = aws.String(input.RoleArn)
+	}
+
+	output, err := s.CognitoClient.CreateGroup(context, createGroupInput)
+
+	if err != nil {
+		return models.Group{}, cognitoError(fmt.Sprintf("Could not create group %s", input.Name), err)
+	}
+
+	return newGroup(output.Group), nil
+}
+
+func (s *AdminService) GetGroup(context context.Context, name string) (models.Group, error) {
+	output, err := s.CognitoClient.GetGroup(context, &cognitoidentityprovider.GetGroupInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		GroupName:  aws.String(name),
+	})
+
+	if err != nil {
+		return models.Group{}, groupError(fmt.Sprintf("Could not get group %s", name), err)
+	}
+
+	return newGroup(output.Group), nil
+}
+
+func (s *AdminService) UpdateGroup(context context.Context, name string, input models.UpdateGroupInput) (models.Group, error) {
+	output, err := s.CognitoClient.UpdateGroup(context, &cognitoidentityprovider.UpdateGroupInput{
+		UserPoolId:  aws.String(s.UserPoolID),
+		GroupName:   aws.String(name),
+		Description: input.Description,
+		Precedence:  input.Precedence,
+		RoleArn:     input.RoleArn,
+	})
+
+	if err != nil {
+		return models.Group{}, groupError(fmt.Sprintf("Could not update group %s", name), err)
+	}
+
+	return newGroup(output.Group), nil
+}
+
+// Deletes the group and removes it from its members, their current tokens keep the old claim
+func (s *AdminService) DeleteGroup(context context.Context, name string) error {
+	_, err := s.CognitoClient.DeleteGroup(context, &cognitoidentityprovider.DeleteGroupInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		GroupName:  aws.String(name),
+	})
+
+	if err != nil {
+		return groupError(fmt.Sprintf("Could not delete group %s", name), err)
+	}
+
+	return nil
+}
+
+func (s *AdminService) ListGroups(context context.Context, input models.PageInput) (models.GroupPage, error) {
+	token, err := decodePageToken(input.NextToken)
+	if err != nil {
+		return models.GroupPage{}, err
+	}
+
+	output, err := s.CognitoClient.ListGroups(context, &cognitoidentityprovider.ListGroupsInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Limit:      s.pageLimit(input.Limit),
+		NextToken:  token,
+	})
+
+	if err != nil {
+		return models.GroupPage{}, cognitoError("Could not list groups", err)
+	}
+
+	return newGroupPage(output.Groups, output.NextToken), nil
+}
+
+func (s *AdminService) ListUsersInGroup(context context.Context, name string, input models.PageInput) (models.UserPage, error) {
+	token, err := decodePageToken(input.NextToken)
+	if err != nil {
+		return models.UserPage{}, err
+	}
+
+	output, err := s.CognitoClient.ListUsersInGroup(context, &cognitoidentityprovider.ListUsersInGroupInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		GroupName:  aws.String(name),
+		Limit:      s.pageLimit(input.Limit),
+		NextToken:  token,
+	})
+
+	if err != nil {
+		return models.UserPage{}, groupError(fmt.Sprintf("Could not list members of group %s", name), err)
+	}
+
+	return newUserPage(output.Users, output.NextToken), nil
+}
+
+func (s *AdminService) ListGroupsForUser(context context.Context, username string, input models.PageInput) (models.GroupPage, error) {
+	token, err := decodePageToken(input.NextToken)
+	if err != nil {
+		return models.GroupPage{}, err
+	}
+
+	output, err := s.CognitoClient.AdminListGroupsForUser(context, &cognitoidentityprovider.AdminListGroupsForUserInput{
+		UserPoolId: aws.String(s.UserPoolID),
+		Username:   aws.String(username),
+		Limit:      s.pageLimit(input.Limit),
+		NextToken:  token,
+	})
+
+	if err != nil {
+		return models.GroupPage{}, cognitoError(fmt.Sprintf("Could not list groups of user %s", username), err)
+	}
+
+	return newGroupPage(output.Groups, output.NextToken), nil
+}
+
+func newGroup(group *types.GroupType) models.Group {
+	return models.Group{
+		Name:        aws.ToString(group.GroupName),
+		Description: aws.ToString(group.Description),
+		Precedence:  group.Precedence,
+		RoleArn:     aws.ToString(group.RoleArn),
+		CreatedAt:   aws.ToTime(group.CreationDate),
+		UpdatedAt:   aws.ToTime(group.LastModifiedDate),
+	}
+}
+
+func newGroupPage(groups []types.GroupType, nextToken *string) models.GroupPage {
+	page := models.GroupPage{Groups: make([]models.Group, 0, len(groups)), NextToken: encodePageToken(nextToken)}
+	for _, group := range groups {
+		page.Groups = append(page.Groups, newGroup(&group))
+	}
+	return page
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"net/url"
+
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+const defaultTOTPIssuer = "go-cognito"
+
+// Starts software token enrollment for the signed in user. The token is not used for MFA until it is verified.
+func (s *AuthService) AssociateSoftwareToken(context context.Context, accessToken, accountName string) (models.SoftwareTokenResponse, error) {
+	output, err := s.CognitoClient.AssociateSoftwareToken(context, &cognitoidentityprovider.AssociateSoftwareTokenInput{
+		AccessToken: aws.String(accessToken),
+	})
+
+	if err != nil {
+		return models.SoftwareTokenResponse{}, cognitoError("Could not associate software token", err)
+	}
+
+	secretCode := aws.ToString(output.SecretCode)
+
+	return models.SoftwareTokenResponse{
+		SecretCode: secretCode,
+		OTPAuthURI: otpAuthURI(s.TOTPIssuer, accountName, secretCode),
+	}, nil
+}
+
+// Confirms enrollment with a code from the authenticator app
+func (s *AuthService) VerifySoftwareToken(context context.Context, accessToken string, input models.VerifySoftwareTokenInput) error {
+	params := &cognitoidentityprovider.VerifySoftwareTokenInput{
+		AccessToken: aws.String(accessToken),
+		UserCode:    aws.String(input.Code),
+	}
+	if input.DeviceName != "" {
+		params.FriendlyDeviceName = aws.String(input.DeviceName)
+	}
+
+	output, err := s.CognitoClient.VerifySoftwareToken(context, params)
+
+	if err != nil {
+		return cognitoError("Could not verify software token", err)
+	}
+
+	if output.Status != types.VerifySoftwareTokenResponseTypeSuccess {
+		return &AuthError{Kind: ErrCodeMismatch, Message: "Software token could not be verified."}
+	}
+
+	return nil
+}
+
+// Enables or disables MFA methods for the signed in user
+func (s *AuthService) SetUserMFAPreference(context context.Context, accessToken string, input models.MFAPreferenceInput) error {
+	params := &cognitoidentityprovider.SetUserMFAPreferenceInput{
+		AccessToken: aws.String(accessToken),
+	}
+	if input.SoftwareToken != nil {
+		params.SoftwareTokenMfaSettings = &types.SoftwareTokenMfaSettingsType{
+			Enabled:      input.SoftwareToken.Enabled,
+			PreferredMfa: input.SoftwareToken.Preferred,
+		}
+	}
+	if input.SMS != nil {
+		params.SMSMfaSettings = &types.SMSMfaSettingsType{
+			Enabled:      input.SMS.Enabled,
+			PreferredMfa: input.SMS.Preferred,
+		}
+	}
+
+	_, err := s.CognitoClient.SetUserMFAPreference(context, params)
+
+	if err != nil {
+		return cognitoError("Could not set MFA preference", err)
+	}
+
+	return nil
+}
+
+// Builds the key URI understood by authenticator apps, see
+// https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func otpAuthURI(issuer, accountName, secretCode string) string {
+	uri := url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + issuer + ":" + accountName,
+		RawQuery: url.Values{
+			"secret": {secretCode},
+			"issuer": {issuer},
+		}.Encode(),
+	}
+	return uri.String()
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Enrolls and enables an authenticator app for the signed in user, returning its secret
+func enableSoftwareToken(t *testing.T, service *AuthService, accessToken string) string {
+	t.Helper()
+
+	ctx := context.Background()
+	token, err := service.AssociateSoftwareToken(ctx, accessToken, testUsername)
+	if err != nil {
+		t.Fatalf("AssociateSoftwareToken() error = %v", err)
+	}
+
+	code, _ := cognitofake.TOTPCode(token.SecretCode, time.Now())
+	if err := service.VerifySoftwareToken(ctx, accessToken, models.VerifySoftwareTokenInput{Code: code}); err != nil {
+		t.Fatalf("VerifySoftwareToken() error = %v", err)
+	}
+
+	preference := models.MFAPreferenceInput{SoftwareToken: &models.MFASettings{Enabled: true, Preferred: true}}
+	if err := service.SetUserMFAPreference(ctx, accessToken, preference); err != nil {
+		t.Fatalf("SetUserMFAPreference() error = %v", err)
+	}
+
+	return token.SecretCode
+}
+
+func TestAssociateSoftwareToken(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	service.TOTPIssuer = "Example App"
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+
+	response, err := service.AssociateSoftwareToken(context.Background(), tokens.AccessToken, testUsername)
+	if err != nil {
+		t.Fatalf("AssociateSoftwareToken() error = %v", err)
+	}
+
+	uri, err := url.Parse(response.OTPAuthURI)
+	if err != nil {
+		t.Fatalf("parse otpauth URI: %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Example App:"+testUsername {
+		t.Fatalf("unexpected otpauth URI: %s", response.OTPAuthURI)
+	}
+	if query := uri.Query(); query.Get("secret") != response.SecretCode || query.Get("issuer") != "Example App" {
+		t.Fatalf("unexpected otpauth query: %s", response.OTPAuthURI)
+	}
+
+	_, err = service.AssociateSoftwareToken(context.Background(), "invalid", testUsername)
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("AssociateSoftwareToken() invalid token error = %v, want %v", err, ErrNotAuthorized)
+	}
+}
+
+func TestVerifySoftwareTokenErrors(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+	ctx := context.Background()
+
+	preference := models.MFAPreferenceInput{SoftwareToken: &models.MFASettings{Enabled: true}}
+	if err := service.SetUserMFAPreference(ctx, tokens.AccessToken, preference); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("SetUserMFAPreference() before verification error = %v, want %v", err, ErrInvalidParameter)
+	}
+
+	if _, err := service.AssociateSoftwareToken(ctx, tokens.AccessToken, testUsername); err != nil {
+		t.Fatalf("AssociateSoftwareToken() error = %v", err)
+	}
+
+	err := service.VerifySoftwareToken(ctx, tokens.AccessToken, models.VerifySoftwareTokenInput{Code: "000000"})
+	if !errors.Is(err, ErrCodeMismatch) {
+		t.Fatalf("VerifySoftwareToken() error = %v, want %v", err, ErrCodeMismatch)
+	}
+}
+
+func TestSignInSoftwareTokenMFA(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	secret := enableSoftwareToken(t, service, signIn(t, service, testPassword).AccessToken)
+	ctx := context.Background()
+
+	challenge := signIn(t, service, testPassword)
+	if challenge.ChallengeName != string(types.ChallengeNameTypeSoftwareTokenMfa) || challenge.Session == "" || challenge.AccessToken != "" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+
+	answer := models.RespondToChallengeInput{UserName: testUsername, ChallengeName: challenge.ChallengeName, Session: challenge.Session}
+	if _, err := service.RespondToChallenge(ctx, answer); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("RespondToChallenge() without code error = %v, want %v", err, ErrInvalidParameter)
+	}
+
+	answer.Code = "000000"
+	if _, err := service.RespondToChallenge(ctx, answer); !errors.Is(err, ErrCodeMismatch) {
+		t.Fatalf("RespondToChallenge() wrong code error = %v, want %v", err, ErrCodeMismatch)
+	}
+
+	// A failed answer uses up the session, so sign in again
+	challenge = signIn(t, service, testPassword)
+	answer.Session = challenge.Session
+	answer.Code, _ = cognitofake.TOTPCode(secret, time.Now())
+
+	response, err := service.RespondToChallenge(ctx, answer)
+	if err != nil {
+		t.Fatalf("RespondToChallenge() error = %v", err)
+	}
+	if response.AccessToken == "" || response.ChallengeName != "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	preference := models.MFAPreferenceInput{SoftwareToken: &models.MFASettings{Enabled: false}}
+	if err := service.SetUserMFAPreference(ctx, response.AccessToken, preference); err != nil {
+		t.Fatalf("SetUserMFAPreference() error = %v", err)
+	}
+	if tokens := signIn(t, service, testPassword); tokens.AccessToken == "" {
+		t.Fatalf("expected tokens once MFA is disabled, got %+v", tokens)
+	}
+}
+
+func TestSignInSMSMFA(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	ctx := context.Background()
+
+	err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane", PhoneNumber: "+14155550100"})
+	if err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	code, _ := fake.Code(testUsername, cognitofake.CodeSignUp)
+	if err := service.ConfirmAccount(ctx, models.UserConfirmationInput{Email: testUsername, Code: code}); err != nil {
+		t.Fatalf("ConfirmAccount() error = %v", err)
+	}
+
+	if user, _ := fake.User(testUsername); user.Attributes["phone_number"] != "+14155550100" {
+		t.Fatalf("phone number was not stored: %+v", user.Attributes)
+	}
+
+	preference := models.MFAPreferenceInput{SMS: &models.MFASettings{Enabled: true, Preferred: true}}
+	if err := service.SetUserMFAPreference(ctx, signIn(t, service, testPassword).AccessToken, preference); err != nil {
+		t.Fatalf("SetUserMFAPreference() error = %v", err)
+	}
+
+	challenge := signIn(t, service, testPassword)
+	if challenge.ChallengeName != string(types.ChallengeNameTypeSmsMfa) || challenge.CodeDeliveryDestination != "+*******0100" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+
+	smsCode, ok := fake.Code(testUsername, cognitofake.CodeSMSMFA)
+	if !ok {
+		t.Fatal("no SMS code was sent")
+	}
+
+	answer := models.RespondToChallengeInput{UserName: testUsername, ChallengeName: challenge.ChallengeName, Session: challenge.Session}
+	if _, err := service.RespondToChallenge(ctx, answer); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("RespondToChallenge() without code error = %v, want %v", err, ErrInvalidParameter)
+	}
+
+	answer.Code = smsCode
+	response, err := service.RespondToChallenge(ctx, answer)
+	if err != nil {
+		t.Fatalf("RespondToChallenge() error = %v", err)
+	}
+	if response.AccessToken == "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	preference = models.MFAPreferenceInput{SMS: &models.MFASettings{Enabled: false}}
+	if err := service.SetUserMFAPreference(ctx, response.AccessToken, preference); err != nil {
+		t.Fatalf("SetUserMFAPreference() error = %v", err)
+	}
+	if tokens := signIn(t, service, testPassword); tokens.AccessToken == "" {
+		t.Fatalf("expected tokens once SMS MFA is disabled, got %+v", tokens)
+	}
+}
+
+func TestSetUserMFAPreferenceSMSRequiresPhoneNumber(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+
+	preference := models.MFAPreferenceInput{SMS: &models.MFASettings{Enabled: true}}
+	err := service.SetUserMFAPreference(context.Background(), signIn(t, service, testPassword).AccessToken, preference)
+	if !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("SetUserMFAPreference() error = %v, want %v", err, ErrInvalidParameter)
+	}
+}
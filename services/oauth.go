@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/go-cognito/models"
+)
+
+// Returned by the hosted UI callback when the state is unknown, expired, already used or was started in another browser
+var ErrInvalidState = errors.New("Sign in request is invalid or has expired, please start again.")
+
+// How long users have to finish signing in at the hosted UI
+const AuthorizationTimeout = 10 * time.Minute
+
+// How long to wait for the token endpoint
+const tokenRequestTimeout = 10 * time.Second
+
+var defaultOAuthScopes = []string{"openid", "email", "profile"}
+
+// Signs users in through the Cognito hosted UI with the authorization code flow and PKCE
+type OAuthService struct {
+	// Verifies the ID token returned by the token endpoint and supplies the app client credentials
+	Auth *AuthService
+	// Hosted UI base URL, e.g. https://myapp.auth.us-east-1.amazoncognito.com
+	Domain string
+	// Where the hosted UI sends users back with a code, must be a callback URL of the app client
+	RedirectURI string
+	// Where the hosted UI sends users after signing out, must be a sign out URL of the app client
+	LogoutURI string
+	Scopes    []string
+	// Pending authorization requests, keyed by state
+	States     StateStore
+	HTTPClient *http.Client
+}
+
+func NewOAuthService(authService *AuthService, domain, redirectURI string) *OAuthService {
+	if authService == nil {
+		log.Fatalf("AuthService cannot be nil.")
+	}
+
+	if domain == "" || redirectURI == "" {
+		log.Fatalf("Hosted UI domain or redirect URI cannot be empty.")
+	}
+
+	return &OAuthService{
+		Auth:        authService,
+		Domain:      strings.TrimSuffix(domain, "/"),
+		RedirectURI: redirectURI,
+		Scopes:      defaultOAuthScopes,
+		States:      NewMemoryStateStore(),
+		HTTPClient:  &http.Client{Timeout: tokenRequestTimeout},
+	}
+}
+
+// Starts a sign in and returns the hosted UI URL to redirect the user to, and the state the callback must be
+// given back by the same browser. Provider skips the hosted UI sign in page and goes straight to an identity
+// provider, e.g. Google.
+func (s *OAuthService) AuthorizeURL(provider string) (authorizeURL, state string, err error) {
+	if state, err = randomToken(); err != nil {
+		return "", "", err
+	}
+	request := AuthorizationRequest{ExpiresAt: time.Now().Add(AuthorizationTimeout)}
+	if request.CodeVerifier, err = randomToken(); err != nil {
+		return "", "", err
+	}
+	if request.Nonce, err = randomToken(); err != nil {
+		return "", "", err
+	}
+
+	s.States.Save(state, request)
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.Auth.ClientID},
+		"redirect_uri":          {s.RedirectURI},
+		"scope":                 {strings.Join(s.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {request.Nonce},
+		"code_challenge":        {codeChallenge(request.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	if provider != "" {
+		query.Set("identity_provider", provider)
+	}
+
+	return s.Domain + "/oauth2/authorize?" + query.Encode(), state, nil
+}
+
+// Exchanges the code the hosted UI sent back for tokens. The state must come from AuthorizeURL and can be used once.
+func (s *OAuthService) HandleCallback(context context.Context, input models.OAuthCallbackInput) (models.AuthResponse, error) {
+	// A callback URL started by someone else would sign the user in to their account
+	if input.BrowserState == "" || subtle.ConstantTimeCompare([]byte(input.BrowserState), []byte(input.State)) != 1 {
+		return models.AuthResponse{}, ErrInvalidState
+	}
+
+	request, ok := s.States.Take(input.State)
+	if !ok || time.Now().After(request.ExpiresAt) {
+		return models.AuthResponse{}, ErrInvalidState
+	}
+
+	// The user cancelled or the identity provider refused the sign in
+	if input.Error != "" {
+		return models.AuthResponse{}, oauthError("Sign in failed", input.Error, input.ErrorDescription)
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {s.Auth.ClientID},
+		"code":          {input.Code},
+		"redirect_uri":  {s.RedirectURI},
+		"code_verifier": {request.CodeVerifier},
+	}
+
+	tokens, err := s.requestTokens(context, form)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	// Make sure the ID token answers this sign in and not one replayed from elsewhere
+	if _, err := s.Auth.VerifyIDToken(tokens.IdToken, request.Nonce); err != nil {
+		return models.AuthResponse{}, &AuthError{Kind: ErrNotAuthorized, Message: "Sign in could not be verified.", Err: err}
+	}
+
+	return tokens, nil
+}
+
+// Returns the hosted UI URL that ends the user's hosted UI session
+func (s *OAuthService) LogoutURL() string {
+	query := url.Values{"client_id": {s.Auth.ClientID}}
+	if s.LogoutURI != "" {
+		query.Set("logout_uri", s.LogoutURI)
+	}
+	return s.Domain + "/logout?" + query.Encode()
+}
+
+// Calls the token endpoint, authenticating with the client secret
+func (s *OAuthService) requestTokens(context context.Context, form url.Values) (models.AuthResponse, error) {
+	request, err := http.NewRequestWithContext(context, http.MethodPost, s.Domain+"/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return models.AuthResponse{}, fmt.Errorf("Could not create token request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(url.QueryEscape(s.Auth.ClientID), url.QueryEscape(s.Auth.ClientSecret))
+
+	response, err := s.HTTPClient.Do(request)
+	if err != nil {
+		return models.AuthResponse{}, fmt.Errorf("Could not reach the token endpoint: %w", err)
+	}
+	defer response.Body.Close()
+
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		IdToken      string `json:"id_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int32  `json:"expires_in"`
+		Error        string `json:"error"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return models.AuthResponse{}, fmt.Errorf("Could not read token response: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return models.AuthResponse{}, oauthError("Could not exchange code", body.Error, response.Status)
+	}
+
+	return models.AuthResponse{
+		AccessToken:  body.AccessToken,
+		IdToken:      body.IdToken,
+		RefreshToken: body.RefreshToken,
+		TokenType:    body.TokenType,
+		ExpiresIn:    body.ExpiresIn,
+	}, nil
+}
+
+// Translates an OAuth error code from the hosted UI or token endpoint, see RFC 6749 sections 4.1.2.1 and 5.2
+func oauthError(context, code, description string) error {
+	message := code
+	if description != "" {
+		message = description
+	}
+
+	switch code {
+	case "invalid_grant", "access_denied", "unauthorized_client":
+		return &AuthError{Kind: ErrNotAuthorized, Message: message}
+	case "invalid_request", "invalid_scope", "unsupported_response_type":
+		return &AuthError{Kind: ErrInvalidParameter, Message: message}
+	}
+	return fmt.Errorf("%s: %s", context, message)
+}
+
+// An authorization request waiting for the hosted UI to redirect back
+type AuthorizationRequest struct {
+	// PKCE secret whose hash was sent to the hosted UI
+	CodeVerifier string
+	// Expected nonce claim of the ID token
+	Nonce     string
+	ExpiresAt time.Time
+}
+
+// Keeps authorization requests between the redirect to the hosted UI and the callback.
+// Replace the in-memory store with a shared one when running more than one instance.
+type StateStore interface {
+	Save(state string, request AuthorizationRequest)
+	// Returns and forgets the request, so each state is used once
+	Take(state string) (AuthorizationRequest, bool)
+}
+
+type MemoryStateStore struct {
+	mu       sync.Mutex
+	requests map[string]AuthorizationRequest
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{requests: map[string]AuthorizationRequest{}}
+}
+
+func (m *MemoryStateStore) Save(state string, request AuthorizationRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Drop requests users never came back from
+	now := time.Now()
+	for key, pending := range m.requests {
+		if now.After(pending.ExpiresAt) {
+			delete(m.requests, key)
+		}
+	}
+
+	m.requests[state] = request
+}
+
+func (m *MemoryStateStore) Take(state string) (AuthorizationRequest, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	request, ok := m.requests[state]
+	delete(m.requests, state)
+	return request, ok
+}
+
+// Returns 32 random bytes, base64url encoded as PKCE verifiers require
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("Could not generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// S256 code challenge of a PKCE verifier, see RFC 7636 section 4.2
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/go-cognito/models"
+)
+
+// Serves /oauth2/token, issuing an ID token with the nonce returned by nonce
+func newTokenEndpoint(t *testing.T, key testKey, nonce func() string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientId, clientSecret, _ := r.BasicAuth()
+		if r.URL.Path != "/oauth2/token" || clientId != "client-id" || clientSecret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		if r.FormValue("code") != "good-code" || r.FormValue("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := idClaims()
+		claims["nonce"] = nonce()
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "access-token",
+			"id_token":      key.sign(t, claims),
+			"refresh_token": "refresh-token",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// Starts a sign in and returns the query of the hosted UI URL
+func authorize(t *testing.T, service *OAuthService, provider string) url.Values {
+	t.Helper()
+
+	authorizeURL, _, err := service.AuthorizeURL(provider)
+	if err != nil {
+		t.Fatalf("AuthorizeURL() error = %v", err)
+	}
+
+	parsed, err := url.Parse(authorizeURL)
+	if err != nil {
+		t.Fatalf("parse authorize URL: %v", err)
+	}
+	return parsed.Query()
+}
+
+func TestAuthorizeURL(t *testing.T) {
+	service := NewOAuthService(newTestAuthService(t, ""), "https://example.auth.us-east-1.amazoncognito.com/", "http://localhost:8080/auth/callback")
+
+	authorizeURL, state, err := service.AuthorizeURL("Google")
+	if err != nil {
+		t.Fatalf("AuthorizeURL() error = %v", err)
+	}
+	if !strings.HasPrefix(authorizeURL, "https://example.auth.us-east-1.amazoncognito.com/oauth2/authorize?") {
+		t.Fatalf("AuthorizeURL() = %s", authorizeURL)
+	}
+
+	parsed, _ := url.Parse(authorizeURL)
+	query := parsed.Query()
+	if query.Get("state") != state {
+		t.Fatalf("state = %s, want %s", query.Get("state"), state)
+	}
+	if query.Get("client_id") != "client-id" || query.Get("identity_provider") != "Google" || query.Get("scope") != "openid email profile" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("redirect_uri") != "http://localhost:8080/auth/callback" {
+		t.Fatalf("unexpected query: %v", query)
+	}
+
+	request, ok := service.States.Take(query.Get("state"))
+	if !ok {
+		t.Fatal("state was not saved")
+	}
+	if codeChallenge(request.CodeVerifier) != query.Get("code_challenge") || request.Nonce != query.Get("nonce") {
+		t.Fatalf("stored request %+v does not match query %v", request, query)
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// Example from RFC 7636 appendix B
+	if got := codeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"); got != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Fatalf("codeChallenge() = %s", got)
+	}
+}
+
+func TestHandleCallback(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	jwks := newJWKSServer(t, key)
+
+	var query url.Values
+	tokenEndpoint := newTokenEndpoint(t, key, func() string { return query.Get("nonce") })
+	service := NewOAuthService(newTestAuthService(t, jwks.URL), tokenEndpoint.URL, "http://localhost:8080/auth/callback")
+	ctx := context.Background()
+
+	query = authorize(t, service, "")
+	tokens, err := service.HandleCallback(ctx, models.OAuthCallbackInput{State: query.Get("state"), BrowserState: query.Get("state"), Code: "good-code"})
+	if err != nil {
+		t.Fatalf("HandleCallback() error = %v", err)
+	}
+	if tokens.AccessToken != "access-token" || tokens.RefreshToken != "refresh-token" || tokens.ExpiresIn != 3600 {
+		t.Fatalf("unexpected tokens: %+v", tokens)
+	}
+
+	// The state is used up
+	if _, err := service.HandleCallback(ctx, models.OAuthCallbackInput{State: query.Get("state"), BrowserState: query.Get("state"), Code: "good-code"}); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("HandleCallback() reused state error = %v, want %v", err, ErrInvalidState)
+	}
+
+	query = authorize(t, service, "")
+	if _, err := service.HandleCallback(ctx, models.OAuthCallbackInput{State: query.Get("state"), BrowserState: query.Get("state"), Code: "bad-code"}); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("HandleCallback() bad code error = %v, want %v", err, ErrNotAuthorized)
+	}
+
+	query = authorize(t, service, "")
+	input := models.OAuthCallbackInput{State: query.Get("state"), BrowserState: query.Get("state"), Error: "access_denied", ErrorDescription: "User cancelled."}
+	if _, err := service.HandleCallback(ctx, input); !errors.Is(err, ErrNotAuthorized) || err.Error() != "User cancelled." {
+		t.Fatalf("HandleCallback() denied error = %v, want %v", err, ErrNotAuthorized)
+	}
+}
+
+func TestHandleCallbackRejectsWrongNonce(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	jwks := newJWKSServer(t, key)
+	tokenEndpoint := newTokenEndpoint(t, key, func() string { return "other-nonce" })
+	service := NewOAuthService(newTestAuthService(t, jwks.URL), tokenEndpoint.URL, "http://localhost:8080/auth/callback")
+
+	query := authorize(t, service, "")
+	_, err := service.HandleCallback(context.Background(), models.OAuthCallbackInput{State: query.Get("state"), BrowserState: query.Get("state"), Code: "good-code"})
+	if !errors.Is(err, ErrNotAuthorized) || !errors.Is(err, ErrInvalidNonce) {
+		t.Fatalf("HandleCallback() error = %v, want %v", err, ErrInvalidNonce)
+	}
+	// The verification details are not shown to the browser
+	if err.Error() != "Sign in could not be verified." {
+		t.Fatalf("HandleCallback() message = %q", err.Error())
+	}
+}
+
+func TestHandleCallbackRejectsOtherBrowser(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	jwks := newJWKSServer(t, key)
+
+	var query url.Values
+	tokenEndpoint := newTokenEndpoint(t, key, func() string { return query.Get("nonce") })
+	service := NewOAuthService(newTestAuthService(t, jwks.URL), tokenEndpoint.URL, "http://localhost:8080/auth/callback")
+	ctx := context.Background()
+
+	// The victim opens a callback URL the attacker stopped at, without or with their own state cookie
+	query = authorize(t, service, "")
+	victim := authorize(t, service, "")
+	for _, browserState := range []string{"", victim.Get("state")} {
+		input := models.OAuthCallbackInput{State: query.Get("state"), BrowserState: browserState, Code: "good-code"}
+		if _, err := service.HandleCallback(ctx, input); !errors.Is(err, ErrInvalidState) {
+			t.Fatalf("HandleCallback() with browser state %q error = %v, want %v", browserState, err, ErrInvalidState)
+		}
+	}
+
+	// The attacker's state is still usable from their own browser
+	input := models.OAuthCallbackInput{State: query.Get("state"), BrowserState: query.Get("state"), Code: "good-code"}
+	if _, err := service.HandleCallback(ctx, input); err != nil {
+		t.Fatalf("HandleCallback() error = %v", err)
+	}
+}
+
+func TestHandleCallbackRejectsExpiredState(t *testing.T) {
+	service := NewOAuthService(newTestAuthService(t, ""), "https://example.auth.us-east-1.amazoncognito.com", "http://localhost:8080/auth/callback")
+	service.States.Save("old-state", AuthorizationRequest{CodeVerifier: "verifier", ExpiresAt: time.Now().Add(-time.Minute)})
+
+	_, err := service.HandleCallback(context.Background(), models.OAuthCallbackInput{State: "old-state", BrowserState: "old-state", Code: "good-code"})
+	if !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("HandleCallback() error = %v, want %v", err, ErrInvalidState)
+	}
+}
+
+func TestLogoutURL(t *testing.T) {
+	service := NewOAuthService(newTestAuthService(t, ""), "https://example.auth.us-east-1.amazoncognito.com", "http://localhost:8080/auth/callback")
+	service.LogoutURI = "http://localhost:3000/"
+
+	want := "https://example.auth.us-east-1.amazoncognito.com/logout?client_id=client-id&logout_uri=http%3A%2F%2Flocalhost%3A3000%2F"
+	if got := service.LogoutURL(); got != want {
+		t.Fatalf("LogoutURL() = %s, want %s", got, want)
+	}
+}
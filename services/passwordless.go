@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Starts a passwordless sign in with the CUSTOM_AUTH flow. The auth challenge triggers email a code,
+// which is answered with RespondToChallenge. A wrong code returns another CUSTOM_CHALLENGE until the
+// attempts run out.
+func (s *AuthService) StartPasswordlessSignIn(context context.Context, user models.PasswordlessSignInInput) (models.AuthResponse, error) {
+	output, err := s.CognitoClient.InitiateAuth(context, &cognitoidentityprovider.InitiateAuthInput{
+		AuthFlow: types.AuthFlowTypeCustomAuth,
+		ClientId: aws.String(s.ClientID),
+		AuthParameters: map[string]string{
+			"USERNAME":    user.UserName,
+			"SECRET_HASH": utils.GetSecretHash(s.ClientID, s.ClientSecret, user.UserName),
+		},
+	})
+
+	if err != nil {
+		return models.AuthResponse{}, cognitoError(fmt.Sprintf("Could not start passwordless sign in for user %s", user.UserName), err)
+	}
+
+	return authResponse(output.AuthenticationResult, output.ChallengeName, output.Session, output.ChallengeParameters)
+}
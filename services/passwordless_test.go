@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+func startPasswordlessSignIn(t *testing.T, service *AuthService) models.AuthResponse {
+	t.Helper()
+
+	challenge, err := service.StartPasswordlessSignIn(context.Background(), models.PasswordlessSignInInput{UserName: testUsername})
+	if err != nil {
+		t.Fatalf("StartPasswordlessSignIn() error = %v", err)
+	}
+	if challenge.ChallengeName != string(types.ChallengeNameTypeCustomChallenge) || challenge.Session == "" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+	return challenge
+}
+
+func answerCode(service *AuthService, challenge models.AuthResponse, code string) (models.AuthResponse, error) {
+	return service.RespondToChallenge(context.Background(), models.RespondToChallengeInput{
+		UserName:      testUsername,
+		ChallengeName: challenge.ChallengeName,
+		Session:       challenge.Session,
+		Code:          code,
+	})
+}
+
+func TestPasswordlessSignIn(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+
+	challenge := startPasswordlessSignIn(t, service)
+	if challenge.ChallengeParameters["email"] != "j***@e***" {
+		t.Fatalf("unexpected challenge parameters: %+v", challenge.ChallengeParameters)
+	}
+
+	if _, err := answerCode(service, challenge, ""); !errors.Is(err, ErrInvalidParameter) {
+		t.Fatalf("RespondToChallenge() without code error = %v, want %v", err, ErrInvalidParameter)
+	}
+
+	// A wrong code gets another round with a new session
+	retry, err := answerCode(service, challenge, "000000")
+	if err != nil {
+		t.Fatalf("RespondToChallenge() wrong code error = %v", err)
+	}
+	if retry.ChallengeName != challenge.ChallengeName || retry.Session == challenge.Session {
+		t.Fatalf("unexpected retry challenge: %+v", retry)
+	}
+
+	code, _ := fake.Code(testUsername, cognitofake.CodePasswordless)
+	response, err := answerCode(service, retry, code)
+	if err != nil {
+		t.Fatalf("RespondToChallenge() error = %v", err)
+	}
+	if response.AccessToken == "" || response.ChallengeName != "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestPasswordlessSignInFailsAfterThreeWrongCodes(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+
+	challenge := startPasswordlessSignIn(t, service)
+	for attempt := 1; attempt < 3; attempt++ {
+		var err error
+		if challenge, err = answerCode(service, challenge, "000000"); err != nil {
+			t.Fatalf("attempt %d error = %v", attempt, err)
+		}
+	}
+
+	if _, err := answerCode(service, challenge, "000000"); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("RespondToChallenge() third wrong code error = %v, want %v", err, ErrNotAuthorized)
+	}
+}
+
+func TestStartPasswordlessSignInErrors(t *testing.T) {
+	service, _ := newFakeAuthService(t)
+	ctx := context.Background()
+
+	_, err := service.StartPasswordlessSignIn(ctx, models.PasswordlessSignInInput{UserName: testUsername})
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("StartPasswordlessSignIn() unknown user error = %v, want %v", err, ErrNotAuthorized)
+	}
+
+	if err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	_, err = service.StartPasswordlessSignIn(ctx, models.PasswordlessSignInInput{UserName: testUsername})
+	if !errors.Is(err, ErrUserNotConfirmed) {
+		t.Fatalf("StartPasswordlessSignIn() unconfirmed error = %v, want %v", err, ErrUserNotConfirmed)
+	}
+}
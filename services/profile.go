@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Standard attributes users may change by default. sub, email_verified and the like are managed by Cognito.
+var defaultEditableAttributes = []string{
+	"name", "given_name", "family_name", "middle_name", "nickname", "preferred_username",
+	"email", "phone_number", "picture", "profile", "website", "gender", "birthdate",
+	"zoneinfo", "locale", "address",
+}
+
+// Attributes every account needs, which can be changed but not removed
+var requiredAttributes = []string{"email", "name"}
+
+func (s *AuthService) GetProfile(context context.Context, accessToken string) (models.UserProfile, error) {
+	output, err := s.CognitoClient.GetUser(context, &cognitoidentityprovider.GetUserInput{
+		AccessToken: aws.String(accessToken),
+	})
+
+	if err != nil {
+		return models.UserProfile{}, cognitoError("Could not get user", err)
+	}
+
+	attributes := make(map[string]string, len(output.UserAttributes))
+	for _, attribute := range output.UserAttributes {
+		attributes[aws.ToString(attribute.Name)] = aws.ToString(attribute.Value)
+	}
+
+	return models.UserProfile{
+		Username:     aws.ToString(output.Username),
+		Attributes:   attributes,
+		EnabledMFA:   output.UserMFASettingList,
+		PreferredMFA: aws.ToString(output.PreferredMfaSetting),
+	}, nil
+}
+
+// Applies the attribute changes and returns where verification codes were sent, e.g. for a new email
+func (s *AuthService) UpdateProfile(context context.Context, accessToken string, input models.UpdateProfileInput) ([]types.CodeDeliveryDetailsType, error) {
+	if err := s.checkAttributeChanges(input); err != nil {
+		return nil, err
+	}
+
+	var codeDeliveryDetails []types.CodeDeliveryDetailsType
+
+	if len(input.Attributes) > 0 {
+		// Sorted so Cognito sees the same request for the same input
+		names := make([]string, 0, len(input.Attributes))
+		for name := range input.Attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		attributes := make([]types.AttributeType, 0, len(names))
+		for _, name := range names {
+			attributes = append(attributes, types.AttributeType{Name: aws.String(name), Value: aws.String(input.Attributes[name])})
+		}
+
+		output, err := s.CognitoClient.UpdateUserAttributes(context, &cognitoidentityprovider.UpdateUserAttributesInput{
+			AccessToken:    aws.String(accessToken),
+			UserAttributes: attributes,
+		})
+
+		if err != nil {
+			return nil, cognitoError("Could not update user attributes", err)
+		}
+
+		codeDeliveryDetails = output.CodeDeliveryDetailsList
+	}
+
+	if len(input.DeleteAttributes) > 0 {
+		_, err := s.CognitoClient.DeleteUserAttributes(context, &cognitoidentityprovider.DeleteUserAttributesInput{
+			AccessToken:        aws.String(accessToken),
+			UserAttributeNames: input.DeleteAttributes,
+		})
+
+		if err != nil {
+			return nil, cognitoError("Could not delete user attributes", err)
+		}
+	}
+
+	return codeDeliveryDetails, nil
+}
+
+func (s *AuthService) DeleteAccount(context context.Context, accessToken string) error {
+	_, err := s.CognitoClient.DeleteUser(context, &cognitoidentityprovider.DeleteUserInput{
+		AccessToken: aws.String(accessToken),
+	})
+
+	if err != nil {
+		return cognitoError("Could not delete user", err)
+	}
+
+	return nil
+}
+
+// Changes the signed in user's password. With SignOutOtherSessions all sessions are revoked and
+// the caller is signed in again with the new password, the returned response replaces its tokens.
+// No tokens are returned when signing out fails, or signing in again fails or needs a challenge
+// answered, the caller then signs in again itself.
+func (s *AuthService) ChangePassword(context context.Context, accessToken, username string, input models.ChangePasswordInput) (*models.AuthResponse, error) {
+	_, err := s.CognitoClient.ChangePassword(context, &cognitoidentityprovider.ChangePasswordInput{
+		AccessToken:      aws.String(accessToken),
+		PreviousPassword: aws.String(input.PreviousPassword),
+		ProposedPassword: aws.String(input.ProposedPassword),
+	})
+
+	if err != nil {
+		return nil, cognitoError("Could not change password", err)
+	}
+
+	if !input.SignOutOtherSessions {
+		return nil, nil
+	}
+
+	// The password has already changed, so failing from here on would send the caller back to the old one.
+	// Cognito can only revoke all of a user's tokens at once.
+	if _, err := s.SignOut(context, models.SignOutInput{AccessToken: accessToken}); err != nil {
+		log.Printf("Couldn't sign user %s out after changing password: %v", username, err)
+		return nil, nil
+	}
+
+	response, err := s.SignIn(context, models.SignInInput{UserName: username, Password: input.ProposedPassword})
+	if err != nil {
+		log.Printf("Couldn't sign user %s in after changing password: %v", username, err)
+		return nil, nil
+	}
+
+	// E.g. an MFA challenge, which the caller answers by signing in
+	if response.AccessToken == "" {
+		return nil, nil
+	}
+
+	return &response, nil
+}
+
+// Confirms a changed email or phone number with the code sent to it
+func (s *AuthService) VerifyAttribute(context context.Context, accessToken string, input models.VerifyAttributeInput) error {
+	_, err := s.CognitoClient.VerifyUserAttribute(context, &cognitoidentityprovider.VerifyUserAttributeInput{
+		AccessToken:   aws.String(accessToken),
+		AttributeName: aws.String(input.Attribute),
+		Code:          aws.String(input.Code),
+	})
+
+	if err != nil {
+		return cognitoError(fmt.Sprintf("Could not verify %s", input.Attribute), err)
+	}
+
+	return nil
+}
+
+// Sends a new verification code for an unverified email or phone number
+func (s *AuthService) ResendAttributeVerificationCode(context context.Context, accessToken string, input models.AttributeVerificationCodeInput) (*types.CodeDeliveryDetailsType, error) {
+	output, err := s.CognitoClient.GetUserAttributeVerificationCode(context, &cognitoidentityprovider.GetUserAttributeVerificationCodeInput{
+		AccessToken:   aws.String(accessToken),
+		AttributeName: aws.String(input.Attribute),
+	})
+
+	if err != nil {
+		return nil, cognitoError(fmt.Sprintf("Could not send %s verification code", input.Attribute), err)
+	}
+
+	return output.CodeDeliveryDetails, nil
+}
+
+// Rejects attributes outside EditableAttributes and the removal of required ones, listing each offender
+func (s *AuthService) checkAttributeChanges(input models.UpdateProfileInput) error {
+	var details []string
+
+	for name := range input.Attributes {
+		if !s.isEditableAttribute(name) {
+			details = append(details, fmt.Sprintf("%s cannot be changed", name))
+		}
+	}
+
+	for _, name := range input.DeleteAttributes {
+		switch {
+		case !s.isEditableAttribute(name):
+			details = append(details, fmt.Sprintf("%s cannot be changed", name))
+		case slices.Contains(requiredAttributes, name):
+			details = append(details, fmt.Sprintf("%s is required and cannot be deleted", name))
+		case input.Attributes[name] != "":
+			details = append(details, fmt.Sprintf("%s cannot be both updated and deleted", name))
+		}
+	}
+
+	if len(details) > 0 {
+		sort.Strings(details)
+		return &AuthError{Kind: ErrInvalidParameter, Message: "Invalid attribute changes.", Details: details}
+	}
+
+	return nil
+}
+
+func (s *AuthService) isEditableAttribute(name string) bool {
+	return slices.Contains(s.EditableAttributes, name)
+}
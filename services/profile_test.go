@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+func TestGetProfile(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+
+	profile, err := service.GetProfile(context.Background(), tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("GetProfile() error = %v", err)
+	}
+
+	if profile.Username != testUsername || profile.Attributes["name"] != "Jane" || profile.Attributes["email_verified"] != "true" {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+
+	if _, err := service.GetProfile(context.Background(), "invalid"); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("GetProfile() invalid token error = %v, want %v", err, ErrNotAuthorized)
+	}
+}
+
+func TestUpdateProfileEmailRequiresVerification(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+	ctx := context.Background()
+
+	input := models.UpdateProfileInput{Attributes: map[string]string{"email": "jane.doe@example.com", "nickname": "JD"}}
+	codeDeliveryDetails, err := service.UpdateProfile(ctx, tokens.AccessToken, input)
+	if err != nil {
+		t.Fatalf("UpdateProfile() error = %v", err)
+	}
+
+	if len(codeDeliveryDetails) != 1 || *codeDeliveryDetails[0].AttributeName != "email" {
+		t.Fatalf("unexpected code delivery details: %+v", codeDeliveryDetails)
+	}
+
+	user, _ := fake.User(testUsername)
+	if user.Attributes["email"] != "jane.doe@example.com" || user.Attributes["nickname"] != "JD" || user.Attributes["email_verified"] != "false" {
+		t.Fatalf("unexpected attributes after update: %v", user.Attributes)
+	}
+
+	verify := models.VerifyAttributeInput{Attribute: "email", Code: "000000"}
+	if err := service.VerifyAttribute(ctx, tokens.AccessToken, verify); !errors.Is(err, ErrCodeMismatch) {
+		t.Fatalf("VerifyAttribute() wrong code error = %v, want %v", err, ErrCodeMismatch)
+	}
+
+	verify.Code, _ = fake.Code(testUsername, cognitofake.CodeVerifyEmail)
+	if err := service.VerifyAttribute(ctx, tokens.AccessToken, verify); err != nil {
+		t.Fatalf("VerifyAttribute() error = %v", err)
+	}
+
+	if user, _ := fake.User(testUsername); user.Attributes["email_verified"] != "true" {
+		t.Fatalf("email_verified = %q, want true", user.Attributes["email_verified"])
+	}
+}
+
+func TestUpdateProfileRejectsAttributes(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+
+	input := models.UpdateProfileInput{
+		Attributes:       map[string]string{"email_verified": "true", "nickname": "JD"},
+		DeleteAttributes: []string{"name", "nickname"},
+	}
+	_, err := service.UpdateProfile(context.Background(), tokens.AccessToken, input)
+
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || authErr.Kind != ErrInvalidParameter {
+		t.Fatalf("UpdateProfile() error = %v, want %v", err, ErrInvalidParameter)
+	}
+
+	want := []string{
+		"email_verified cannot be changed",
+		"name is required and cannot be deleted",
+		"nickname cannot be both updated and deleted",
+	}
+	if !slices.Equal(authErr.Details, want) {
+		t.Fatalf("Details = %q, want %q", authErr.Details, want)
+	}
+
+	// Nothing is sent to Cognito when any change is rejected
+	if user, _ := fake.User(testUsername); user.Attributes["nickname"] != "" {
+		t.Fatalf("nickname = %q, want it unchanged", user.Attributes["nickname"])
+	}
+}
+
+func TestUpdateProfileCustomAttributes(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	service.EditableAttributes = append(service.EditableAttributes, "custom:team")
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+	ctx := context.Background()
+
+	add := models.UpdateProfileInput{Attributes: map[string]string{"custom:team": "platform"}}
+	if _, err := service.UpdateProfile(ctx, tokens.AccessToken, add); err != nil {
+		t.Fatalf("UpdateProfile() error = %v", err)
+	}
+
+	remove := models.UpdateProfileInput{DeleteAttributes: []string{"custom:team"}}
+	if _, err := service.UpdateProfile(ctx, tokens.AccessToken, remove); err != nil {
+		t.Fatalf("UpdateProfile() delete error = %v", err)
+	}
+
+	if user, _ := fake.User(testUsername); user.Attributes["custom:team"] != "" {
+		t.Fatalf("custom:team = %q, want it deleted", user.Attributes["custom:team"])
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+	ctx := context.Background()
+
+	if err := service.DeleteAccount(ctx, tokens.AccessToken); err != nil {
+		t.Fatalf("DeleteAccount() error = %v", err)
+	}
+
+	if _, ok := fake.User(testUsername); ok {
+		t.Fatal("user still exists after DeleteAccount()")
+	}
+
+	if _, err := service.GetProfile(ctx, tokens.AccessToken); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("GetProfile() after delete error = %v, want %v", err, ErrNotAuthorized)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	tokens := signIn(t, service, testPassword)
+	ctx := context.Background()
+
+	wrong := models.ChangePasswordInput{PreviousPassword: "Wrong-123", ProposedPassword: "Password-456"}
+	if _, err := service.ChangePassword(ctx, tokens.AccessToken, testUsername, wrong); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("ChangePassword() wrong password error = %v, want %v", err, ErrNotAuthorized)
+	}
+
+	weak := models.ChangePasswordInput{PreviousPassword: testPassword, ProposedPassword: "password"}
+	_, err := service.ChangePassword(ctx, tokens.AccessToken, testUsername, weak)
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || authErr.Kind != ErrInvalidPassword || len(authErr.Details) == 0 {
+		t.Fatalf("ChangePassword() weak password error = %#v, want %v with details", err, ErrInvalidPassword)
+	}
+
+	input := models.ChangePasswordInput{PreviousPassword: testPassword, ProposedPassword: "Password-456"}
+	authResult, err := service.ChangePassword(ctx, tokens.AccessToken, testUsername, input)
+	if err != nil || authResult != nil {
+		t.Fatalf("ChangePassword() = %v, %v, want no tokens", authResult, err)
+	}
+
+	// Without signing out the current session stays valid
+	if _, err := service.GetProfile(ctx, tokens.AccessToken); err != nil {
+		t.Fatalf("GetProfile() error = %v", err)
+	}
+	signIn(t, service, "Password-456")
+}
+
+func TestChangePasswordSignOutOtherSessions(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	signUpConfirmed(t, service, fake)
+	current := signIn(t, service, testPassword)
+	other := signIn(t, service, testPassword)
+	ctx := context.Background()
+
+	input := models.ChangePasswordInput{PreviousPassword: testPassword, ProposedPassword: "Password-456", SignOutOtherSessions: true}
+	authResult, err := service.ChangePassword(ctx, current.AccessToken, testUsername, input)
+	if err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if authResult == nil || authResult.AccessToken == "" {
+		t.Fatalf("ChangePassword() = %+v, want new tokens", authResult)
+	}
+
+	for _, accessToken := range []string{current.AccessToken, other.AccessToken} {
+		if _, err := service.GetProfile(ctx, accessToken); !errors.Is(err, ErrNotAuthorized) {
+			t.Fatalf("GetProfile() with revoked token error = %v, want %v", err, ErrNotAuthorized)
+		}
+	}
+
+	if _, err := service.GetProfile(ctx, authResult.AccessToken); err != nil {
+		t.Fatalf("GetProfile() with new token error = %v", err)
+	}
+}
+
+func TestChangePasswordSignOutOtherSessionsWithoutSignIn(t *testing.T) {
+	service, fake := newFakeAuthService(t)
+	ctx := context.Background()
+
+	if err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane", PhoneNumber: "+14155550100"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	code, _ := fake.Code(testUsername, cognitofake.CodeSignUp)
+	if err := service.ConfirmAccount(ctx, models.UserConfirmationInput{Email: testUsername, Code: code}); err != nil {
+		t.Fatalf("ConfirmAccount() error = %v", err)
+	}
+
+	// Signing in again is throttled
+	accessToken := signIn(t, service, testPassword).AccessToken
+	fake.FailNext("InitiateAuth", &types.TooManyRequestsException{Message: aws.String("Too many requests.")})
+	input := models.ChangePasswordInput{PreviousPassword: testPassword, ProposedPassword: "Password-456", SignOutOtherSessions: true}
+	authResult, err := service.ChangePassword(ctx, accessToken, testUsername, input)
+	if err != nil || authResult != nil {
+		t.Fatalf("ChangePassword() throttled sign in = %v, %v, want no tokens", authResult, err)
+	}
+	if user, _ := fake.User(testUsername); user.Password != "Password-456" {
+		t.Fatal("password was not changed")
+	}
+
+	// Signing out is throttled, the password still changes
+	accessToken = signIn(t, service, "Password-456").AccessToken
+	fake.FailNext("GlobalSignOut", &types.TooManyRequestsException{Message: aws.String("Too many requests.")})
+	input = models.ChangePasswordInput{PreviousPassword: "Password-456", ProposedPassword: "Password-457", SignOutOtherSessions: true}
+	authResult, err = service.ChangePassword(ctx, accessToken, testUsername, input)
+	if err != nil || authResult != nil {
+		t.Fatalf("ChangePassword() throttled sign out = %v, %v, want no tokens", authResult, err)
+	}
+	if user, _ := fake.User(testUsername); user.Password != "Password-457" {
+		t.Fatal("password was not changed")
+	}
+
+	// Signing in again needs an SMS MFA code
+	preference := models.MFAPreferenceInput{SMS: &models.MFASettings{Enabled: true, Preferred: true}}
+	if err := service.SetUserMFAPreference(ctx, signIn(t, service, "Password-457").AccessToken, preference); err != nil {
+		t.Fatalf("SetUserMFAPreference() error = %v", err)
+	}
+	challenge := signIn(t, service, "Password-457")
+	smsCode, _ := fake.Code(testUsername, cognitofake.CodeSMSMFA)
+	tokens, err := service.RespondToChallenge(ctx, models.RespondToChallengeInput{UserName: testUsername, ChallengeName: challenge.ChallengeName, Session: challenge.Session, Code: smsCode})
+	if err != nil {
+		t.Fatalf("RespondToChallenge() error = %v", err)
+	}
+
+	input = models.ChangePasswordInput{PreviousPassword: "Password-457", ProposedPassword: "Password-789", SignOutOtherSessions: true}
+	authResult, err = service.ChangePassword(ctx, tokens.AccessToken, testUsername, input)
+	if err != nil || authResult != nil {
+		t.Fatalf("ChangePassword() challenged sign in = %+v, %v, want no tokens", authResult, err)
+	}
+	if user, _ := fake.User(testUsername); user.Password != "Password-789" {
+		t.Fatal("password was not changed")
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"example.com/go-cognito/models"
+	"example.com/go-cognito/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Signs in with USER_SRP_AUTH, proving knowledge of the password to Cognito without sending it
+func (s *AuthService) signInWithSRP(context context.Context, user models.SignInInput) (models.AuthResponse, error) {
+	srpClient, err := utils.NewSRPClient(s.UserPoolID)
+
+	if err != nil {
+		return models.AuthResponse{}, fmt.Errorf("Could not start SRP sign in: %w", err)
+	}
+
+	output, err := s.CognitoClient.InitiateAuth(context, &cognitoidentityprovider.InitiateAuthInput{
+		AuthFlow: types.AuthFlowTypeUserSrpAuth,
+		ClientId: aws.String(s.ClientID),
+		AuthParameters: map[string]string{
+			"USERNAME":    user.UserName,
+			"SRP_A":       srpClient.SRPA(),
+			"SECRET_HASH": utils.GetSecretHash(s.ClientID, s.ClientSecret, user.UserName),
+		},
+	})
+
+	if err != nil {
+		return models.AuthResponse{}, cognitoError(fmt.Sprintf("Could not sign in user %s", user.UserName), err)
+	}
+
+	if output.ChallengeName != types.ChallengeNameTypePasswordVerifier {
+		return models.AuthResponse{}, errors.New("Expected PASSWORD_VERIFIER challenge from SRP sign in.")
+	}
+
+	responses, err := srpClient.PasswordVerifierResponses(user.Password, output.ChallengeParameters, time.Now())
+
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	// Cognito may identify the user by sub, the secret hash must match the USERNAME sent back
+	responses["SECRET_HASH"] = utils.GetSecretHash(s.ClientID, s.ClientSecret, responses["USERNAME"])
+
+	challengeOutput, err := s.CognitoClient.RespondToAuthChallenge(context, &cognitoidentityprovider.RespondToAuthChallengeInput{
+		ClientId:           aws.String(s.ClientID),
+		ChallengeName:      types.ChallengeNameTypePasswordVerifier,
+		Session:            output.Session,
+		ChallengeResponses: responses,
+	})
+
+	if err != nil {
+		return models.AuthResponse{}, cognitoError(fmt.Sprintf("Could not sign in user %s", user.UserName), err)
+	}
+
+	return authResponse(challengeOutput.AuthenticationResult, challengeOutput.ChallengeName, challengeOutput.Session, challengeOutput.ChallengeParameters)
+}
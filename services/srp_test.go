@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/go-cognito/cognitofake"
+	"example.com/go-cognito/models"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+func newSRPAuthService(t *testing.T) (*AuthService, *cognitofake.Client) {
+	t.Helper()
+
+	service, fake := newFakeAuthService(t)
+	service.AuthFlow = types.AuthFlowTypeUserSrpAuth
+	fake.UserPoolID = service.UserPoolID
+
+	return service, fake
+}
+
+func TestSignInWithSRP(t *testing.T) {
+	service, fake := newSRPAuthService(t)
+	signUpConfirmed(t, service, fake)
+
+	response := signIn(t, service, testPassword)
+	if response.AccessToken == "" || response.IdToken == "" || response.RefreshToken == "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestSignInWithSRPErrors(t *testing.T) {
+	service, fake := newSRPAuthService(t)
+	ctx := context.Background()
+
+	_, err := service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: testPassword})
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("SignIn() unknown user error = %v, want %v", err, ErrNotAuthorized)
+	}
+
+	if err := service.SignUp(ctx, models.SignUpInput{UserName: testUsername, Password: testPassword, Name: "Jane"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	_, err = service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: testPassword})
+	if !errors.Is(err, ErrUserNotConfirmed) {
+		t.Fatalf("SignIn() unconfirmed error = %v, want %v", err, ErrUserNotConfirmed)
+	}
+
+	code, _ := fake.Code(testUsername, cognitofake.CodeSignUp)
+	if err := service.ConfirmAccount(ctx, models.UserConfirmationInput{Email: testUsername, Code: code}); err != nil {
+		t.Fatalf("ConfirmAccount() error = %v", err)
+	}
+
+	_, err = service.SignIn(ctx, models.SignInInput{UserName: testUsername, Password: "Wrong-Password-1"})
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("SignIn() wrong password error = %v, want %v", err, ErrNotAuthorized)
+	}
+}
+
+func TestSignInWithSRPThenMFA(t *testing.T) {
+	service, fake := newSRPAuthService(t)
+	signUpConfirmed(t, service, fake)
+	secret := enableSoftwareToken(t, service, signIn(t, service, testPassword).AccessToken)
+
+	challenge := signIn(t, service, testPassword)
+	if challenge.ChallengeName != string(types.ChallengeNameTypeSoftwareTokenMfa) {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+
+	code, _ := cognitofake.TOTPCode(secret, time.Now())
+	response, err := service.RespondToChallenge(context.Background(), models.RespondToChallengeInput{
+		UserName:      testUsername,
+		ChallengeName: challenge.ChallengeName,
+		Session:       challenge.Session,
+		Code:          code,
+	})
+	if err != nil {
+		t.Fatalf("RespondToChallenge() error = %v", err)
+	}
+	if response.AccessToken == "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
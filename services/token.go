@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"example.com/go-cognito/models"
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Errors returned when a token fails verification
+var (
+	ErrTokenInvalid     = errors.New("The token is not valid.")
+	ErrTokenExpired     = errors.New("Token has expired.")
+	ErrTokenNotYetValid = errors.New("Token is not valid yet.")
+	ErrInvalidIssuer    = errors.New("Token was not issued by this user pool.")
+	ErrInvalidClientID  = errors.New("Token was not issued to an allowed app client.")
+	ErrInvalidAudience  = errors.New("Token audience is not an allowed app client.")
+	ErrInvalidTokenUse  = errors.New("Invalid token use.")
+	ErrInvalidNonce     = errors.New("Token nonce does not match.")
+)
+
+// Values of the token_use claim
+const (
+	TokenUseAccess = "access"
+	TokenUseID     = "id"
+)
+
+// Returns the issuer of tokens signed by a Cognito user pool
+func CognitoIssuer(region, userPoolId string) string {
+	if region == "" || userPoolId == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", region, userPoolId)
+}
+
+// Returns the JWKS URL published by a Cognito user pool
+func cognitoJWKSURL(region, userPoolId string) string {
+	issuer := CognitoIssuer(region, userPoolId)
+	if issuer == "" {
+		return ""
+	}
+	return issuer + "/.well-known/jwks.json"
+}
+
+// Returns the JWKS shared by every request to this service, creating it on first use.
+// The key set refreshes itself in the background and immediately when a token carries an unknown kid.
+func (s *AuthService) keyfunc() (keyfunc.Keyfunc, error) {
+	s.jwksMu.Lock()
+	defer s.jwksMu.Unlock()
+
+	if s.jwks != nil {
+		return s.jwks, nil
+	}
+
+	if s.JWKSURL == "" {
+		return nil, fmt.Errorf("Region or User Pool ID environment variables are not set.")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	jwks, err := keyfunc.NewDefaultCtx(ctx, []string{s.JWKSURL})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("Failed to create JWKS from URL: %v", err)
+	}
+
+	s.jwks = jwks
+	s.jwksCancel = cancel
+
+	return s.jwks, nil
+}
+
+// Stops the background JWKS refresh
+func (s *AuthService) Close() {
+	s.jwksMu.Lock()
+	defer s.jwksMu.Unlock()
+
+	if s.jwksCancel != nil {
+		s.jwksCancel()
+	}
+	s.jwks = nil
+	s.jwksCancel = nil
+}
+
+// Verifies a Cognito access token and returns its claims
+func (s *AuthService) VerifyToken(jwtToken string) (*models.AccessTokenClaims, error) {
+	claims := &models.AccessTokenClaims{}
+
+	if err := s.parseToken(jwtToken, claims); err != nil {
+		return nil, err
+	}
+
+	if claims.TokenUse != TokenUseAccess {
+		return nil, fmt.Errorf("%w Must be an access token.", ErrInvalidTokenUse)
+	}
+
+	if !s.isAllowedClient(claims.ClientID) {
+		return nil, ErrInvalidClientID
+	}
+
+	// Access tokens carry no aud claim, but if one is present it must name an allowed client as well
+	for _, audience := range claims.Audience {
+		if !s.isAllowedClient(audience) {
+			return nil, ErrInvalidClientID
+		}
+	}
+
+	return claims, nil
+}
+
+// Verifies a Cognito ID token and returns its claims.
+// If nonce is not empty it must match the nonce claim sent with the authorization request.
+func (s *AuthService) VerifyIDToken(jwtToken, nonce string) (*models.IDTokenClaims, error) {
+	claims := &models.IDTokenClaims{}
+
+	if err := s.parseToken(jwtToken, claims); err != nil {
+		return nil, err
+	}
+
+	if claims.TokenUse != TokenUseID {
+		return nil, fmt.Errorf("%w Must be an ID token.", ErrInvalidTokenUse)
+	}
+
+	// ID tokens are issued to a single app client named in aud
+	if len(claims.Audience) != 1 || !s.isAllowedClient(claims.Audience[0]) {
+		return nil, ErrInvalidAudience
+	}
+
+	if nonce != "" && subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, ErrInvalidNonce
+	}
+
+	return claims, nil
+}
+
+// Returns the token_use claim without verifying the token, so callers can pick a verifier
+func TokenUse(jwtToken string) (string, error) {
+	claims := jwt.MapClaims{}
+
+	if _, _, err := jwt.NewParser().ParseUnverified(jwtToken, claims); err != nil {
+		return "", fmt.Errorf("%w (%v)", ErrTokenInvalid, err)
+	}
+
+	tokenUse, _ := claims["token_use"].(string)
+	return tokenUse, nil
+}
+
+// Checks the signature, issuer and lifetime of a token and decodes it into claims
+func (s *AuthService) parseToken(jwtToken string, claims jwt.Claims) error {
+	if s.Issuer == "" {
+		return fmt.Errorf("Region or User Pool ID environment variables are not set.")
+	}
+
+	jwks, err := s.keyfunc()
+	if err != nil {
+		return err
+	}
+
+	_, err = jwt.ParseWithClaims(jwtToken, claims, jwks.Keyfunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithIssuer(s.Issuer),
+		jwt.WithLeeway(s.ClockSkew),
+	)
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return ErrInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing) && isMissingIssuer(claims):
+		return ErrInvalidIssuer
+	default:
+		return fmt.Errorf("%w (%v)", ErrTokenInvalid, err)
+	}
+}
+
+func isMissingIssuer(claims jwt.Claims) bool {
+	issuer, err := claims.GetIssuer()
+	return err == nil && issuer == ""
+}
+
+func (s *AuthService) isAllowedClient(clientId string) bool {
+	for _, allowed := range s.AllowedClientIDs {
+		if clientId != "" && clientId == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type testKey struct {
+	kid     string
+	private *rsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, kid string) testKey {
+	t.Helper()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return testKey{kid: kid, private: private}
+}
+
+func (k testKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = k.kid
+
+	signed, err := token.SignedString(k.private)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// Serves a JWKS document and counts how often it is fetched
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []testKey
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keys ...testKey) *jwksServer {
+	t.Helper()
+
+	server := &jwksServer{keys: keys}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.fetches.Add(1)
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+
+		set := map[string][]map[string]string{"keys": {}}
+		for _, key := range server.keys {
+			set["keys"] = append(set["keys"], map[string]string{
+				"kty": "RSA",
+				"kid": key.kid,
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.private.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.private.E)).Bytes()),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *jwksServer) setKeys(keys ...testKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func newTestAuthService(t *testing.T, jwksURL string) *AuthService {
+	t.Helper()
+
+	client := cognitoidentityprovider.New(cognitoidentityprovider.Options{Region: "us-east-1"})
+	service := NewAuthService(client, "client-id", "client-secret", "us-east-1", "us-east-1_pool")
+	service.JWKSURL = jwksURL
+	t.Cleanup(service.Close)
+
+	return service
+}
+
+const testIssuer = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_pool"
+
+func accessClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":       testIssuer,
+		"sub":       "user-sub",
+		"token_use": "access",
+		"client_id": "client-id",
+		"iat":       time.Now().Unix(),
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifyTokenReusesJWKS(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	server := newJWKSServer(t, key)
+	service := newTestAuthService(t, server.URL)
+
+	for i := 0; i < 3; i++ {
+		claims, err := service.VerifyToken(key.sign(t, accessClaims()))
+		if err != nil {
+			t.Fatalf("VerifyToken() error = %v", err)
+		}
+		if claims.Subject != "user-sub" {
+			t.Fatalf("claims.Subject = %q, want %q", claims.Subject, "user-sub")
+		}
+	}
+
+	if got := server.fetches.Load(); got != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", got)
+	}
+}
+
+func TestVerifyTokenRefreshesOnUnknownKID(t *testing.T) {
+	oldKey := newTestKey(t, "key-1")
+	server := newJWKSServer(t, oldKey)
+	service := newTestAuthService(t, server.URL)
+
+	if _, err := service.VerifyToken(oldKey.sign(t, accessClaims())); err != nil {
+		t.Fatalf("VerifyToken() with initial key: %v", err)
+	}
+
+	newKey := newTestKey(t, "key-2")
+	server.setKeys(oldKey, newKey)
+
+	if _, err := service.VerifyToken(newKey.sign(t, accessClaims())); err != nil {
+		t.Fatalf("VerifyToken() with rotated key: %v", err)
+	}
+
+	if got := server.fetches.Load(); got != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", got)
+	}
+}
+
+func TestVerifyTokenRejectsUnknownSigner(t *testing.T) {
+	server := newJWKSServer(t, newTestKey(t, "key-1"))
+	service := newTestAuthService(t, server.URL)
+
+	forged := newTestKey(t, "key-1")
+	if _, err := service.VerifyToken(forged.sign(t, accessClaims())); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("VerifyToken() with forged signature error = %v, want %v", err, ErrTokenInvalid)
+	}
+}
+
+func TestVerifyTokenRejectsIDToken(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	server := newJWKSServer(t, key)
+	service := newTestAuthService(t, server.URL)
+
+	claims := accessClaims()
+	claims["token_use"] = "id"
+
+	if _, err := service.VerifyToken(key.sign(t, claims)); !errors.Is(err, ErrInvalidTokenUse) {
+		t.Fatalf("VerifyToken() with id token error = %v, want %v", err, ErrInvalidTokenUse)
+	}
+}
+
+func TestVerifyTokenRejectsExpiredToken(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	server := newJWKSServer(t, key)
+	service := newTestAuthService(t, server.URL)
+
+	claims := accessClaims()
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	if _, err := service.VerifyToken(key.sign(t, claims)); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("VerifyToken() with expired token error = %v, want %v", err, ErrTokenExpired)
+	}
+}
+
+func TestVerifyTokenReturnsClaims(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	server := newJWKSServer(t, key)
+	service := newTestAuthService(t, server.URL)
+
+	raw := accessClaims()
+	raw["username"] = "jane"
+	raw["scope"] = "aws.cognito.signin.user.admin orders/read"
+	raw["cognito:groups"] = []string{"admin", "support"}
+	raw["auth_time"] = 1700000000
+	raw["jti"] = "token-id"
+
+	claims, err := service.VerifyToken(key.sign(t, raw))
+	if err != nil {
+		t.Fatalf("VerifyToken() error = %v", err)
+	}
+
+	if claims.Username != "jane" || claims.ClientID != "client-id" || claims.ID != "token-id" || claims.AuthTime != 1700000000 {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+	if !claims.InGroup("support") || claims.InGroup("billing") {
+		t.Fatalf("unexpected groups: %v", claims.Groups)
+	}
+}
+
+func TestVerifyTokenValidatesIssuerAndClient(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	server := newJWKSServer(t, key)
+	service := newTestAuthService(t, server.URL)
+	service.AllowedClientIDs = append(service.AllowedClientIDs, "other-client-id")
+
+	tests := []struct {
+		name    string
+		mutate  func(jwt.MapClaims)
+		wantErr error
+	}{
+		{"valid", func(jwt.MapClaims) {}, nil},
+		{"additional allowed client", func(c jwt.MapClaims) { c["client_id"] = "other-client-id" }, nil},
+		{"other user pool", func(c jwt.MapClaims) { c["iss"] = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_other" }, ErrInvalidIssuer},
+		{"missing issuer", func(c jwt.MapClaims) { delete(c, "iss") }, ErrInvalidIssuer},
+		{"unknown client", func(c jwt.MapClaims) { c["client_id"] = "unknown-client" }, ErrInvalidClientID},
+		{"missing client", func(c jwt.MapClaims) { delete(c, "client_id") }, ErrInvalidClientID},
+		{"unknown audience", func(c jwt.MapClaims) { c["aud"] = "unknown-client" }, ErrInvalidClientID},
+		{"issued in the future", func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() }, ErrTokenNotYetValid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := accessClaims()
+			tt.mutate(claims)
+
+			_, err := service.VerifyToken(key.sign(t, claims))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyTokenAppliesClockSkew(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	server := newJWKSServer(t, key)
+	service := newTestAuthService(t, server.URL)
+
+	claims := accessClaims()
+	claims["exp"] = time.Now().Add(-10 * time.Second).Unix()
+	token := key.sign(t, claims)
+
+	if _, err := service.VerifyToken(token); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("VerifyToken() without leeway error = %v, want %v", err, ErrTokenExpired)
+	}
+
+	service.ClockSkew = time.Minute
+	if _, err := service.VerifyToken(token); err != nil {
+		t.Fatalf("VerifyToken() with leeway error = %v", err)
+	}
+}
+
+func idClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":              testIssuer,
+		"sub":              "user-sub",
+		"aud":              "client-id",
+		"token_use":        "id",
+		"cognito:username": "jane",
+		"email":            "jane@example.com",
+		"email_verified":   true,
+		"name":             "Jane",
+		"iat":              time.Now().Unix(),
+		"exp":              time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	server := newJWKSServer(t, key)
+	service := newTestAuthService(t, server.URL)
+
+	claims, err := service.VerifyIDToken(key.sign(t, idClaims()), "")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+
+	if claims.Email != "jane@example.com" || !claims.EmailVerified || claims.Name != "Jane" || claims.Username != "jane" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerifyIDTokenValidatesClaims(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	server := newJWKSServer(t, key)
+	service := newTestAuthService(t, server.URL)
+
+	tests := []struct {
+		name    string
+		mutate  func(jwt.MapClaims)
+		nonce   string
+		wantErr error
+	}{
+		{"matching nonce", func(c jwt.MapClaims) { c["nonce"] = "abc" }, "abc", nil},
+		{"nonce not checked when empty", func(c jwt.MapClaims) { c["nonce"] = "abc" }, "", nil},
+		{"mismatched nonce", func(c jwt.MapClaims) { c["nonce"] = "abc" }, "xyz", ErrInvalidNonce},
+		{"missing nonce", func(jwt.MapClaims) {}, "xyz", ErrInvalidNonce},
+		{"unknown audience", func(c jwt.MapClaims) { c["aud"] = "unknown-client" }, "", ErrInvalidAudience},
+		{"missing audience", func(c jwt.MapClaims) { delete(c, "aud") }, "", ErrInvalidAudience},
+		{"access token", func(c jwt.MapClaims) { c["token_use"] = "access" }, "", ErrInvalidTokenUse},
+		{"other user pool", func(c jwt.MapClaims) { c["iss"] = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_other" }, "", ErrInvalidIssuer},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, "", ErrTokenExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := idClaims()
+			tt.mutate(claims)
+
+			_, err := service.VerifyIDToken(key.sign(t, claims), tt.nonce)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyIDToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenUse(t *testing.T) {
+	key := newTestKey(t, "key-1")
+
+	if use, err := TokenUse(key.sign(t, idClaims())); err != nil || use != TokenUseID {
+		t.Fatalf("TokenUse(id token) = %q, %v; want %q", use, err, TokenUseID)
+	}
+	if _, err := TokenUse("not-a-jwt"); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("TokenUse(garbage) error = %v, want %v", err, ErrTokenInvalid)
+	}
+}
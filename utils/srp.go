@@ -0,0 +1,208 @@
+// Secure Remote Password (SRP-6a) as used by Cognito's USER_SRP_AUTH flow
+
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// 3072-bit group from RFC 3526 with generator 2, the group Cognito uses
+const srpNHex = "FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1" +
+	"29024E088A67CC74020BBEA63B139B22514A08798E3404DD" +
+	"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245" +
+	"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED" +
+	"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D" +
+	"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F" +
+	"83655D23DCA3AD961C62F356208552BB9ED529077096966D" +
+	"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B" +
+	"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9" +
+	"DE2BCBF6955817183995497CEA956AE515D2261898FA0510" +
+	"15728E5A8AAAC42DAD33170D04507A33A85521ABDF1CBA64" +
+	"ECFB850458DBEF0A8AEA71575D060C7DB3970F85A6E1E4C7" +
+	"ABF5AE8CDB0933D71E8C94E04A25619DCEE3D2261AD2EE6B" +
+	"F12FFA06D98A0864D87602733EC86A64521F2B18177B200C" +
+	"BBE117577A615D6C770988C0BAD946E208E24FA074E5AB31" +
+	"43DB5BFCE0FD108E4B82D120A93AD2CAFFFFFFFFFFFFFFFF"
+
+// Format of the TIMESTAMP challenge response, the day of month is not zero padded
+const SRPTimestampLayout = "Mon Jan 2 15:04:05 UTC 2006"
+
+var (
+	srpN, _ = new(big.Int).SetString(srpNHex, 16)
+	srpG    = big.NewInt(2)
+	srpK    = hexHash("00" + srpNHex + "02")
+)
+
+// Context string Cognito mixes into the derived key
+var srpInfo = []byte("Caldera Derived Key\x01")
+
+// Client half of the exchange: send SRPA with InitiateAuth, then answer PASSWORD_VERIFIER
+type SRPClient struct {
+	userPoolId string
+	a          *big.Int
+	A          *big.Int
+}
+
+// Creates a client with a fresh ephemeral key, use one per sign in
+func NewSRPClient(userPoolId string) (*SRPClient, error) {
+	random := make([]byte, 128)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+
+	return newSRPClient(userPoolId, new(big.Int).SetBytes(random))
+}
+
+func newSRPClient(userPoolId string, a *big.Int) (*SRPClient, error) {
+	a = new(big.Int).Mod(a, srpN)
+	A := new(big.Int).Exp(srpG, a, srpN)
+
+	if A.Sign() == 0 {
+		return nil, errors.New("SRP public value A must not be zero.")
+	}
+
+	return &SRPClient{userPoolId: userPoolId, a: a, A: A}, nil
+}
+
+// Public value sent as the SRP_A auth parameter
+func (c *SRPClient) SRPA() string {
+	return c.A.Text(16)
+}
+
+// Computes the answer to the PASSWORD_VERIFIER challenge. The map holds TIMESTAMP, USERNAME,
+// PASSWORD_CLAIM_SECRET_BLOCK and PASSWORD_CLAIM_SIGNATURE, the caller adds SECRET_HASH.
+func (c *SRPClient) PasswordVerifierResponses(password string, challengeParameters map[string]string, now time.Time) (map[string]string, error) {
+	userIdForSRP := challengeParameters["USER_ID_FOR_SRP"]
+	secretBlock := challengeParameters["SECRET_BLOCK"]
+	salt := challengeParameters["SALT"]
+
+	B, ok := new(big.Int).SetString(challengeParameters["SRP_B"], 16)
+	if !ok || userIdForSRP == "" || secretBlock == "" || salt == "" {
+		return nil, errors.New("PASSWORD_VERIFIER challenge is missing SRP parameters.")
+	}
+
+	if new(big.Int).Mod(B, srpN).Sign() == 0 {
+		return nil, errors.New("SRP public value B must not be zero.")
+	}
+
+	u := SRPScramble(c.A, B)
+	if u.Sign() == 0 {
+		return nil, errors.New("SRP scrambling parameter must not be zero.")
+	}
+
+	x, err := SRPPrivateKey(c.userPoolId, userIdForSRP, password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	// S = (B - k * g^x) ^ (a + u * x) mod N
+	base := new(big.Int).Exp(srpG, x, srpN)
+	base.Mul(base, srpK)
+	base.Sub(B, base)
+	base.Mod(base, srpN)
+
+	exponent := new(big.Int).Mul(u, x)
+	exponent.Add(exponent, c.a)
+
+	S := new(big.Int).Exp(base, exponent, srpN)
+
+	secretBlockBytes, err := base64.StdEncoding.DecodeString(secretBlock)
+	if err != nil {
+		return nil, errors.New("PASSWORD_VERIFIER challenge has an invalid SECRET_BLOCK.")
+	}
+
+	timestamp := now.UTC().Format(SRPTimestampLayout)
+
+	return map[string]string{
+		"TIMESTAMP":                   timestamp,
+		"USERNAME":                    userIdForSRP,
+		"PASSWORD_CLAIM_SECRET_BLOCK": secretBlock,
+		"PASSWORD_CLAIM_SIGNATURE":    SRPSignature(SRPSessionKey(S, u), c.userPoolId, userIdForSRP, secretBlockBytes, timestamp),
+	}, nil
+}
+
+// Returns N, g and k of the SRP group, for implementing the server side in tests
+func SRPGroup() (n, g, k *big.Int) {
+	return new(big.Int).Set(srpN), new(big.Int).Set(srpG), new(big.Int).Set(srpK)
+}
+
+// Derives x from the password and the hex salt chosen by Cognito
+func SRPPrivateKey(userPoolId, userIdForSRP, password, salt string) (*big.Int, error) {
+	// Hashed as a number like the AWS JS client does, so leading zero bytes are dropped
+	saltValue, ok := new(big.Int).SetString(salt, 16)
+	if !ok || saltValue.Sign() < 0 {
+		return nil, errors.New("SRP salt must be hex encoded.")
+	}
+
+	identity := sha256.Sum256([]byte(srpPoolName(userPoolId) + userIdForSRP + ":" + password))
+
+	return hexHash(padHex(saltValue) + hex.EncodeToString(identity[:])), nil
+}
+
+// Computes u = H(A | B)
+func SRPScramble(A, B *big.Int) *big.Int {
+	return hexHash(padHex(A) + padHex(B))
+}
+
+// Derives the 16 byte signing key from the shared secret S with HKDF-SHA256, salted with u
+func SRPSessionKey(S, u *big.Int) []byte {
+	ikm, _ := hex.DecodeString(padHex(S))
+	salt, _ := hex.DecodeString(padHex(u))
+
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write(srpInfo)
+
+	return expand.Sum(nil)[:16]
+}
+
+// Signs the pool name, user, secret block and timestamp with the session key
+func SRPSignature(key []byte, userPoolId, userIdForSRP string, secretBlock []byte, timestamp string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(srpPoolName(userPoolId)))
+	mac.Write([]byte(userIdForSRP))
+	mac.Write(secretBlock)
+	mac.Write([]byte(timestamp))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// The part of the user pool ID after the region, e.g. "abc123" for "us-east-1_abc123"
+func srpPoolName(userPoolId string) string {
+	if _, name, found := strings.Cut(userPoolId, "_"); found {
+		return name
+	}
+	return userPoolId
+}
+
+// Hashes the bytes encoded by a hex string into an integer
+func hexHash(value string) *big.Int {
+	bytes, _ := hex.DecodeString(value)
+	sum := sha256.Sum256(bytes)
+	return new(big.Int).SetBytes(sum[:])
+}
+
+func padHex(value *big.Int) string {
+	return padHexString(value.Text(16))
+}
+
+// Pads to whole bytes and keeps the value positive when read as two's complement
+func padHexString(value string) string {
+	switch {
+	case len(value)%2 == 1:
+		return "0" + value
+	case value != "" && strings.ContainsAny(value[:1], "89abcdefABCDEF"):
+		return "00" + value
+	}
+	return value
+}
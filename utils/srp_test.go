@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Vectors computed with a Python script (hashlib, hmac and pow) following AuthenticationHelper
+// in amazon-cognito-identity-js, the AWS JS client
+const (
+	srpTestPoolId      = "us-east-1_ExAmPlE"
+	srpTestUser        = "jane@example.com"
+	srpTestPassword    = "Password-123"
+	srpTestSalt        = "0fd1c2b3a4958677"
+	srpTestSecretBlock = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	srpTestTimestamp   = "Mon Jan 2 15:04:05 UTC 2006"
+
+	srpTestK = "538282c4354742d7cbbde2359fcf67f9f5b3a6b08791e5011b43b8a5b66d9ee6"
+	srpTestA = "78604b754341df90d89b6b6f863685f06670aa11b23e06bd273c7d699d8589b9217b0bd9c6779c5bf67889259965c46f9e1d688ee977d8606ddc9ffe5ce6403c8d9598e8e9dc3bfc9e12e19b586dbc85f4ecce8211f350f2364d0fd24c3c0deed7783d90f121c7c6f2ee7064e63d88d8fd1b0a7b3dfbe9bcd92db247c7c76b2a50d49ab4f6a68165f0192a088475da953aefeb4ee1fedc8f5488e01e89bfc8b3a2d0f1fd1f28829422c6999579ac84ec676994449cca9ba82311b40894153a00c8ba843bfb528e18d9cc6b0a532f305b5d250469983c989c2a332f7ae252845fb7d4188656f1c87ed94115108304dc5a2926fa7bd95d0e6a074cb9f3bc120275bef33f509482a5a9854935c830f6753e6d5d38be77d24819a1eb4286d228c1270d68e7aa5db7d33acc638600bfba76af6f6293c055ababd1b8f57b6e72b67f6c3253cd0053c37dda63d7bb0a1267397d78413e0c272f2789ddc707116ad8e2fa198848aacba9111264499e264060cbb03c3b25f4c3bf323a8128a67cc4688292"
+	srpTestB = "2b04d687b9262f65b7ecc7a755e5c62bba7ff1bcf5c6183e34f8fa978e9f294116ececac60c33799c98afc21e50206d8a7bfbaaee99bd9611844b498a3af1f29174fbfcc9dd429cdf4cb184a725eeb1690b19381f02da38ef7b1e486c4b5baf6c8549e3c5b8413f25cd308fa31828f39070e2f12563cb825903b9ed65ca531f98d38821fe732c357365ee0a55734bf660608327b9ff647ba707199c9ed757a5af636bdb2190e6496e0910d86d317d367b37645f8eedfea77f58e269deadb9c78f4f0cca66acbdd3e83d6fb4ab5b617d54087196057f781425c32ef5d8659216d055c958eec6fe2754b94a3d8758542450ef64d3bf1f7edc7693ab7520dbdb42d26c5dfa4b6fcf5ca6cc3adbb3a46e6a84f6d3e602c06799eb4f3967f002bf2df9cc006dc60a72dd9a5456f7d45ee626d3b4012790c90349b625e66e49891d3f7e4eb9431a9bd37b1a8df2bdb4d07c4259cdb861caeead8b52fa86a5afe35692dd3a81243492f96e723c96567fc4f09f6dcb6964d48d204622e762384170e5666"
+	srpTestU = "7947d61fda3d3739afdbfa803bacea951588e146ebfa0a6338c7b46262a73bea"
+	srpTestX = "fa212b50c7377de663b2bf1f3383a68808e85b17beb6c7fcd00b8db0d88fcbfb"
+	srpTestS = "87a00571ad8fc0f37200d562121b61da84a0ee6d9b4df8172bbc4021f9dd0a860ec183cd2db8e76e07d2b45790462f2a45a3f52004a5396f63d277b503d707487a05f4681bdc0d5a4288641682858f5d01617a93ffa6fd02a07925ae7c2f010af60270093c709bc323f8db8d89e319879c170c3535f0b6fd9cba93040a6edd72927c8658f9ab6a6424108ad91f5077a9d0c7a75972c917d0b3a5d211ee617617ed27c0020a9952489c0708112461ce9ba0ffc66c57debb12cd249fd2ac9e16b344d47be54cd9ef15b5b448dd25d484e0e1376a32691c5ef9443bb0c17145b58ac007c4f9504da1e946c8d8cff49412e942f414c69aa82154c06868b92603dbb775c028196b65906aed0fbf9fc58610293a8e2812c0b80a463137bf84be73928483c966e55e6d0006fb48285573f45e30700c59012df942eeb73c034d412429b22366f5e752d981dbd5578874b6c28a35989c2695f93ad88ad1b44b321355fe151528ec0090aba8f6f47a7b836d7c4317c97831946933df67b08657f658ab4527"
+
+	srpTestKey       = "8e1edcdefa80d91f42dcb1bc6d1d95a8"
+	srpTestSignature = "DoCCXcjyzDd/R6dFB9NgW+OuNrL8J3QeDqpcjS3spjw="
+
+	// Salt with leading zero bytes, which the JS client hashes as 00d1c2b3a49586
+	srpTestZeroSalt  = "0000d1c2b3a49586"
+	srpTestZeroSaltX = "7a9a38720c0bb1cd6e82578d8c8b520e95b0eec91b520fff051c9ec0fd7559ff"
+)
+
+func hexInt(t *testing.T, value string) *big.Int {
+	t.Helper()
+
+	n, ok := new(big.Int).SetString(value, 16)
+	if !ok {
+		t.Fatalf("invalid hex %q", value)
+	}
+	return n
+}
+
+func testSRPClient(t *testing.T) *SRPClient {
+	t.Helper()
+
+	client, err := newSRPClient(srpTestPoolId, hexInt(t, strings.Repeat("a1", 32)))
+	if err != nil {
+		t.Fatalf("newSRPClient() error = %v", err)
+	}
+	return client
+}
+
+func TestSRPGroup(t *testing.T) {
+	n, g, k := SRPGroup()
+
+	if n.BitLen() != 3072 || !n.ProbablyPrime(20) || g.Int64() != 2 {
+		t.Fatalf("unexpected group N=%x g=%d", n, g)
+	}
+	if k.Text(16) != srpTestK {
+		t.Fatalf("k = %x, want %s", k, srpTestK)
+	}
+
+	// Callers must not be able to change the group
+	n.SetInt64(1)
+	if srpN.BitLen() != 3072 {
+		t.Fatal("SRPGroup() exposed the package state")
+	}
+}
+
+func TestSRPA(t *testing.T) {
+	if got := testSRPClient(t).SRPA(); got != srpTestA {
+		t.Fatalf("SRPA() = %s, want %s", got, srpTestA)
+	}
+}
+
+func TestSRPIntermediateValues(t *testing.T) {
+	A, B := hexInt(t, srpTestA), hexInt(t, srpTestB)
+
+	if u := SRPScramble(A, B); u.Text(16) != srpTestU {
+		t.Fatalf("SRPScramble() = %x, want %s", u, srpTestU)
+	}
+
+	x, err := SRPPrivateKey(srpTestPoolId, srpTestUser, srpTestPassword, srpTestSalt)
+	if err != nil {
+		t.Fatalf("SRPPrivateKey() error = %v", err)
+	}
+	if x.Text(16) != srpTestX {
+		t.Fatalf("SRPPrivateKey() = %x, want %s", x, srpTestX)
+	}
+
+	key := SRPSessionKey(hexInt(t, srpTestS), hexInt(t, srpTestU))
+	if got := big.NewInt(0).SetBytes(key).Text(16); got != srpTestKey || len(key) != 16 {
+		t.Fatalf("SRPSessionKey() = %x, want %s", key, srpTestKey)
+	}
+
+	x, err = SRPPrivateKey(srpTestPoolId, srpTestUser, srpTestPassword, srpTestZeroSalt)
+	if err != nil || x.Text(16) != srpTestZeroSaltX {
+		t.Fatalf("SRPPrivateKey() with leading zero salt = %x, %v, want %s", x, err, srpTestZeroSaltX)
+	}
+
+	if _, err := SRPPrivateKey(srpTestPoolId, srpTestUser, srpTestPassword, "not-hex"); err == nil {
+		t.Fatal("SRPPrivateKey() accepted a salt that is not hex")
+	}
+}
+
+func TestPasswordVerifierResponses(t *testing.T) {
+	now, _ := time.Parse(SRPTimestampLayout, srpTestTimestamp)
+
+	responses, err := testSRPClient(t).PasswordVerifierResponses(srpTestPassword, map[string]string{
+		"USER_ID_FOR_SRP": srpTestUser,
+		"SRP_B":           srpTestB,
+		"SALT":            srpTestSalt,
+		"SECRET_BLOCK":    srpTestSecretBlock,
+	}, now)
+	if err != nil {
+		t.Fatalf("PasswordVerifierResponses() error = %v", err)
+	}
+
+	want := map[string]string{
+		"TIMESTAMP":                   srpTestTimestamp,
+		"USERNAME":                    srpTestUser,
+		"PASSWORD_CLAIM_SECRET_BLOCK": srpTestSecretBlock,
+		"PASSWORD_CLAIM_SIGNATURE":    srpTestSignature,
+	}
+	for name, value := range want {
+		if responses[name] != value {
+			t.Errorf("%s = %q, want %q", name, responses[name], value)
+		}
+	}
+}
+
+func TestPasswordVerifierResponsesRejectsBadParameters(t *testing.T) {
+	client := testSRPClient(t)
+	valid := map[string]string{
+		"USER_ID_FOR_SRP": srpTestUser,
+		"SRP_B":           srpTestB,
+		"SALT":            srpTestSalt,
+		"SECRET_BLOCK":    srpTestSecretBlock,
+	}
+
+	tests := map[string]map[string]string{
+		"missing B":       {"SRP_B": ""},
+		"B is zero mod N": {"SRP_B": srpNHex},
+		"bad salt":        {"SALT": "xyz"},
+		"bad secret":      {"SECRET_BLOCK": "%%%"},
+	}
+
+	for name, overrides := range tests {
+		t.Run(name, func(t *testing.T) {
+			parameters := map[string]string{}
+			for key, value := range valid {
+				parameters[key] = value
+			}
+			for key, value := range overrides {
+				parameters[key] = value
+			}
+
+			if _, err := client.PasswordVerifierResponses(srpTestPassword, parameters, time.Now()); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestSRPTimestampLayout(t *testing.T) {
+	at := time.Date(2024, time.March, 5, 9, 7, 3, 0, time.UTC)
+	if got := at.Format(SRPTimestampLayout); got != "Tue Mar 5 09:07:03 UTC 2024" {
+		t.Fatalf("timestamp = %q", got)
+	}
+}
+
+func TestPadHex(t *testing.T) {
+	tests := map[string]string{
+		"f":    "0f",
+		"7f":   "7f",
+		"80":   "0080",
+		"abc":  "0abc",
+		"1234": "1234",
+	}
+	for value, want := range tests {
+		if got := padHexString(value); got != want {
+			t.Errorf("padHexString(%q) = %q, want %q", value, got, want)
+		}
+	}
+}